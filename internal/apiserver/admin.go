@@ -0,0 +1,30 @@
+package apiserver
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+// requireAdmin returns the connected user if he is an administrator.
+func (apiServer *APIServer) requireAdmin(c *websocket.Client) (*model.User, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if !user.IsAdmin {
+		return nil, apierror.ErrorPermissionDenied
+	}
+	return user, nil
+}
@@ -1,14 +1,24 @@
 package apiserver
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	"github.com/armantarkhanian/jwt"
 	"github.com/armantarkhanian/websocket"
 	"github.com/centrifugal/centrifuge"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/avatar"
 	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"github.com/renju24/backend/internal/pkg/moderation"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+	"github.com/renju24/backend/internal/pkg/ratelimit"
+	"github.com/renju24/backend/internal/pkg/securetoken"
+	"github.com/renju24/backend/internal/pkg/webpush"
+	"github.com/renju24/backend/model"
 	"github.com/rs/zerolog"
 )
 
@@ -22,6 +32,24 @@ type APIServer struct {
 	config         *config.Config
 	jwt            *jwt.EncodeDecoder
 	centrifugeNode *centrifuge.Node
+	messageFilter  moderation.Filter
+	pushSender     *webpush.Sender
+	mailer         *mailer.Mailer
+	tokenSigner    *securetoken.Signer
+	oauthProviders *oauth.Registry
+	sessionClients *sessionClients
+	avatars        *avatar.Store
+	botUser        *model.User
+
+	passwordResetEmailLimiter *ratelimit.Limiter
+	passwordResetIPLimiter    *ratelimit.Limiter
+	twoFactorLimiter          *ratelimit.Limiter
+	authIPLimiter             *ratelimit.Limiter
+	signInLoginLimiter        *ratelimit.Limiter
+	signInLockout             *ratelimit.Lockout
+	rpcLimiter                *rpcLimiter
+	avatarLimiter             *ratelimit.Limiter
+	exportLimiter             *ratelimit.Limiter
 
 	// Dependecies.
 	db Database
@@ -64,28 +92,72 @@ func initApi(runMode string, db Database, router *gin.Engine, logger *zerolog.Lo
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
-	logger.Info().Interface("config", config).Send()
+	logger.Info().Interface("config", config.Redacted()).Send()
 
 	jwtEncodeDecoder, err := jwt.New(config.Server.Token.SigningKey)
 	if err != nil {
 		logger.Fatal().Err(err).Send()
 	}
 
+	oauthProviders, err := oauth.NewRegistry(config.Oauth2)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
 	a := &APIServer{
-		runMode:      runMode,
-		addr:         ":8008",
-		router:       router,
-		logger:       logger,
-		config:       config,
-		jwt:          jwtEncodeDecoder,
-		db:           db,
-		ConfigReader: configReader,
+		runMode:        runMode,
+		addr:           ":8008",
+		router:         router,
+		logger:         logger,
+		config:         config,
+		jwt:            jwtEncodeDecoder,
+		messageFilter:  moderation.NewWordListFilter(config.Chat.WordLists),
+		tokenSigner:    securetoken.NewSigner(config.Server.Token.SigningKey),
+		oauthProviders: oauthProviders,
+		sessionClients: newSessionClients(),
+		avatars:        avatar.NewStore(avatarDir(config.Account)),
+		db:             db,
+		ConfigReader:   configReader,
 	}
 
 	if a.runMode == "prod" {
 		a.addr = ":443"
 	}
 
+	a.passwordResetEmailLimiter = newRateLimiter(config.PasswordReset.PerEmail, defaultPasswordResetPerEmail)
+	a.passwordResetIPLimiter = newRateLimiter(config.PasswordReset.PerIP, defaultPasswordResetPerIP)
+	a.twoFactorLimiter = newRateLimiter(config.TwoFactor.Attempts, defaultTwoFactorAttempts)
+	a.authIPLimiter = newRateLimiter(config.RateLimit.AuthPerIP, defaultAuthPerIP)
+	a.signInLoginLimiter = newRateLimiter(config.RateLimit.SignInPerLogin, defaultSignInPerLogin)
+	a.signInLockout = newLockout(config.RateLimit.Lockout)
+	a.rpcLimiter = newRPCLimiter(config.RateLimit)
+	a.avatarLimiter = newRateLimiter(config.RateLimit.AvatarUploads, defaultAvatarUploads)
+	a.exportLimiter = newRateLimiter(config.RateLimit.DataExports, defaultDataExports)
+
+	if err = a.initBot(); err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	a.mailer, err = newMailer(config.Mail, db, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+	go a.mailer.Run(context.Background())
+	go a.runNotificationDigest(context.Background())
+
+	// Web Push is disabled if VAPID key is not configured.
+	if config.WebPush.VAPIDPrivateKey != "" {
+		a.pushSender, err = webpush.NewSender(
+			config.WebPush.VAPIDPrivateKey,
+			config.WebPush.Subject,
+			time.Duration(config.WebPush.TTL)*time.Second,
+			nil,
+		)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+	}
+
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
 	corsConfig.AllowWebSockets = true
@@ -118,29 +190,32 @@ func initApi(runMode string, db Database, router *gin.Engine, logger *zerolog.Lo
 	a.router.StaticFile("/manifest.json", "./internal/apiserver/front/manifest.json")
 	a.router.StaticFile("/robots.txt", "./internal/apiserver/front/robots.txt")
 	a.router.Static("/static", "./internal/apiserver/front/static")
+	a.router.Static(avatarsPath, a.avatars.Dir())
 
-	a.router.GET("/logout", func(c *gin.Context) {
-		c.SetCookie(
-			a.config.Server.Token.Cookie.Name,
-			"",
-			-1,
-			a.config.Server.Token.Cookie.Path,
-			a.config.Server.Token.Cookie.Domain,
-			false,
-			false,
-		)
-		c.Redirect(http.StatusFound, "/")
-	})
+	a.router.GET("/logout", logout(a))
 
 	// POST /api/v1/*
 	apiRoutes := a.router.Group("/api/v1")
 	{
-		apiRoutes.POST("/sign_up", signUp(a))
-		apiRoutes.POST("/sign_in", signIn(a))
+		authIPLimit := rateLimitMiddleware(a, a.authIPLimiter, clientIPKey)
+		apiRoutes.POST("/sign_up", authIPLimit, signUp(a))
+		apiRoutes.POST("/sign_in", authIPLimit, rateLimitMiddleware(a, a.signInLoginLimiter, signInLoginKey), signIn(a))
+		apiRoutes.POST("/sign_in/two_factor", authIPLimit, signInTwoFactor(a))
+		apiRoutes.POST("/refresh", refreshSession(a))
 		apiRoutes.GET("/ping", func(c *gin.Context) { c.String(http.StatusOK, "PONG") })
 		apiRoutes.GET("/oauth2/:platform/services", oauth2Services(a))
 		apiRoutes.GET("/oauth2/:platform/:service", oauth2Login(a))
 		apiRoutes.GET("/oauth2/:platform/:service/callback", oauth2Callback(a))
+		apiRoutes.POST("/oauth2/:platform/:service/token", authIPLimit, oauth2Token(a))
+		apiRoutes.GET("/oauth2/:platform/:service/link", oauth2Link(a))
+		apiRoutes.GET("/web_push/public_key", webPushPublicKey(a))
+		apiRoutes.GET("/verify_email", verifyEmail(a))
+		apiRoutes.POST("/password_reset/request", requestPasswordReset(a))
+		apiRoutes.POST("/password_reset/confirm", authIPLimit, confirmPasswordReset(a))
+		apiRoutes.POST("/avatar", uploadAvatar(a))
+		apiRoutes.DELETE("/avatar", deleteAvatar(a))
+		apiRoutes.GET("/export", exportData(a))
+		apiRoutes.GET("/games/:id/record", downloadGameRecord(a))
 	}
 
 	// Initialize WebSocket server.
@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"time"
+
+	"github.com/renju24/backend/model"
+	"github.com/renju24/backend/pkg/ai"
+	pkggame "github.com/renju24/backend/pkg/game"
+)
+
+const (
+	defaultBotUsername  = "renju_bot"
+	defaultBotLevel     = 3
+	defaultBotMoveDelay = 700 * time.Millisecond
+)
+
+func (api *APIServer) initBot() error {
+	username := api.config.Bot.Username
+	if username == "" {
+		username = defaultBotUsername
+	}
+	user, err := api.db.GetOrCreateBotUser(username)
+	if err != nil {
+		return err
+	}
+	api.botUser = user
+	return nil
+}
+
+func (api *APIServer) isBot(userID int64) bool {
+	return api.botUser != nil && api.botUser.ID == userID
+}
+
+// botLevel returns the default level if user hasn't chosen it.
+func (api *APIServer) botLevel(level int) (ai.Level, bool) {
+	if level == 0 {
+		level = api.config.Bot.DefaultLevel
+	}
+	if level == 0 {
+		level = defaultBotLevel
+	}
+	if ai.Level(level) < ai.MinLevel || ai.Level(level) > ai.MaxLevel {
+		return 0, false
+	}
+	return ai.Level(level), true
+}
+
+func (api *APIServer) botMoveDelay() time.Duration {
+	if api.config.Bot.MoveDelay > 0 {
+		return time.Duration(api.config.Bot.MoveDelay) * time.Millisecond
+	}
+	return defaultBotMoveDelay
+}
+
+// playBotMove makes the bot's move in the game through makeMove, so it's checked and published as the user's move.
+func (api *APIServer) playBotMove(gameID int64) {
+	start := time.Now()
+	game, err := api.db.GetGameByID(gameID)
+	if err != nil {
+		api.logger.Error().Err(err).Send()
+		return
+	}
+	if game.Status != model.InProgress || game.BotLevel == nil {
+		return
+	}
+	bot, err := ai.New(ai.Level(*game.BotLevel))
+	if err != nil {
+		api.logger.Error().Err(err).Int64("game_id", gameID).Send()
+		return
+	}
+	moves, err := api.db.GetGameMovesByID(gameID)
+	if err != nil {
+		api.logger.Error().Err(err).Send()
+		return
+	}
+	board := pkggame.NewGame()
+	for _, move := range moves {
+		if _, err = board.ApplyMove(pkggame.NewMove(move.XCoordinate, move.YCoordinate, game.GetColorByUserID(move.UserID))); err != nil {
+			api.logger.Error().Err(err).Int64("game_id", gameID).Send()
+			return
+		}
+	}
+	if game.GetUserIDByColor(board.Turn()) != api.botUser.ID {
+		return
+	}
+	x, y, err := bot.Move(board)
+	if err != nil {
+		api.logger.Error().Err(err).Int64("game_id", gameID).Send()
+		return
+	}
+	// Instant replies look unnatural and the user may not be subscribed to the game yet.
+	if delay := api.botMoveDelay() - time.Since(start); delay > 0 {
+		time.Sleep(delay)
+	}
+	if err = api.makeMove(api.botUser.ID, &RPCMakeMoveRequest{
+		GameID:      gameID,
+		XCoordinate: x,
+		YCoordinate: y,
+	}); err != nil {
+		api.logger.Error().Err(err).Int64("game_id", gameID).Int("x", x).Int("y", y).Msg("bot move failed")
+	}
+}
@@ -1,11 +1,18 @@
 package apiserver
 
 import (
+	"time"
+
+	"github.com/renju24/backend/internal/pkg/mailer"
 	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+	"github.com/renju24/backend/internal/pkg/webpush"
 	"github.com/renju24/backend/model"
 )
 
 type Database interface {
+	// Email outbox.
+	mailer.Outbox
+
 	// Create new user.
 	CreateUser(username, email, passwordBcrypt string) (*model.User, error)
 
@@ -18,8 +25,8 @@ type Database interface {
 	// Get user by ID.
 	GetUserByID(userID int64) (*model.User, error)
 
-	// Create new game.
-	CreateGame(blackUserID, whiteUserID int64) (gameID int64, err error)
+	// Create new game, unrated games don't change rankings.
+	CreateGame(blackUserID, whiteUserID int64, rated bool) (gameID int64, err error)
 
 	// Delete a game.
 	DeleteGame(gameID int64) error
@@ -42,8 +49,8 @@ type Database interface {
 	// Returns the playing game.
 	GetPlayingGame(userID int64) (*model.PlayingGame, error)
 
-	// Find users by username.
-	FindUsers(username string) ([]*model.User, error)
+	// Find users by username, hiding users blocked by viewer or who blocked viewer.
+	FindUsers(username string, viewerID int64) ([]*model.User, error)
 
 	// Get game history by username.
 	GameHistory(username string) ([]model.GameHistoryItem, error)
@@ -60,6 +67,193 @@ type Database interface {
 	// Set game status to Finished.
 	FinishGameWithWinner(gameID, winnerID int64) error
 
+	// Create new chat message in a game.
+	CreateMessage(gameID, userID int64, text string) (*model.Message, error)
+
+	// Get chat message by ID.
+	GetMessageByID(messageID int64) (*model.Message, error)
+
+	// Get all chat messages of a game.
+	GetGameMessages(gameID int64) ([]model.Message, error)
+
+	// Delete chat message.
+	DeleteMessage(messageID int64) error
+
+	// Returns the active mute of user or nil.
+	GetActiveMute(userID int64) (*model.Mute, error)
+
+	// Forbid user to send chat messages until mutedUntil.
+	MuteUser(userID, mutedBy int64, reason string, mutedUntil time.Time) error
+
+	// Remove user's mute.
+	UnmuteUser(userID int64) error
+
+	// Report a chat message.
+	CreateReport(messageID, reporterID int64, reason string) (reportID int64, err error)
+
+	// List reports, optionally only unresolved ones.
+	ListReports(onlyOpen bool) ([]model.Report, error)
+
+	// Mark report as resolved.
+	ResolveReport(reportID, resolvedBy int64) error
+
+	// Get friendship status from the point of view of userID.
+	GetFriendshipStatus(userID, otherID int64) (model.FriendshipStatus, error)
+
+	// Create new friend request.
+	CreateFriendRequest(requesterID, addresseeID int64) error
+
+	// Accept pending friend request.
+	AcceptFriendRequest(requesterID, addresseeID int64) error
+
+	// Delete pending friend request.
+	DeleteFriendRequest(requesterID, addresseeID int64) error
+
+	// Remove user from friends.
+	RemoveFriend(userID, friendID int64) error
+
+	// Get user's friends.
+	GetFriends(userID int64) ([]*model.User, error)
+
+	// Get user's incoming and outgoing pending friend requests.
+	GetFriendRequests(userID int64) ([]model.FriendRequest, error)
+
+	// Block user.
+	BlockUser(userID, blockedUserID int64) error
+
+	// Unblock user.
+	UnblockUser(userID, blockedUserID int64) error
+
+	// Has one of the users blocked the other one?
+	IsBlocked(userID, otherID int64) (bool, error)
+
+	// Get users blocked by user.
+	GetBlockedUsers(userID int64) ([]*model.User, error)
+
+	// Store new notification for user.
+	CreateNotification(userID int64, eventType string, data []byte) (*model.Notification, error)
+
+	// List user's notifications from newest to oldest.
+	ListNotifications(userID int64, unreadOnly bool, beforeID int64, limit int) ([]model.Notification, error)
+
+	// Count user's unread notifications.
+	CountUnreadNotifications(userID int64) (int, error)
+
+	// Mark notifications as read, all of them if notificationIDs is empty.
+	MarkNotificationsRead(userID int64, notificationIDs []int64) error
+
+	// Save browser's push subscription for user.
+	SavePushSubscription(userID int64, sub *webpush.Subscription) error
+
+	// Delete user's push subscription.
+	DeletePushSubscription(userID int64, endpoint string) error
+
+	// Get all user's push subscriptions.
+	GetPushSubscriptions(userID int64) ([]*webpush.Subscription, error)
+
+	// Get users who should receive unread notifications digest.
+	NotificationDigests(olderThan time.Time) ([]model.NotificationDigest, error)
+
+	// Mark notifications as included in a digest.
+	MarkNotificationsDigested(userID int64, olderThan time.Time) error
+
+	// Store email verification token hash.
+	CreateEmailVerificationToken(userID int64, email, tokenHash string, expiresAt time.Time) error
+
+	// Get the time of the last verification email sent to user.
+	LastEmailVerificationSentAt(userID int64) (time.Time, error)
+
+	// Use email verification token and mark user's email as verified.
+	VerifyEmail(tokenHash string) (userID int64, err error)
+
+	// Store password reset token hash.
+	CreatePasswordResetToken(userID int64, tokenHash string, expiresAt time.Time) error
+
+	// Use password reset token, set new password and revoke user's sessions.
+	ResetPassword(tokenHash, passwordBcrypt string, revokedAt time.Time) (userID int64, err error)
+
+	// Set new password and revoke user's other sessions.
+	UpdatePassword(userID int64, passwordBcrypt string, exceptSessionID int64, revokedAt time.Time) ([]int64, error)
+
+	// Set new unverified email.
+	UpdateEmail(userID int64, email string) error
+
+	// Rename user and reserve the old username.
+	ChangeUsername(userID int64, username string, changeInterval, reservation time.Duration) error
+
+	// Link OAuth2 account to the existing user.
+	LinkOauth(userID int64, oauthID string, service oauth.Service) error
+
+	// Unlink OAuth2 account if user has another login method.
+	UnlinkOauth(userID int64, service oauth.Service) error
+
+	// Get OAuth2 services linked to the user.
+	GetOauthServices(userID int64) ([]oauth.Service, error)
+
+	// Create session of the signed in device.
+	CreateSession(userID int64, tokenHash, userAgent, ip string, expiresAt time.Time) (sessionID int64, err error)
+
+	// Get session by ID.
+	GetSession(sessionID int64) (*model.Session, error)
+
+	// Replace the refresh token of the session, revoke the session if the token is reused.
+	RotateSession(tokenHash, newTokenHash, ip string, expiresAt time.Time) (*model.Session, error)
+
+	// Get not revoked and not expired sessions of the user.
+	GetActiveSessions(userID int64) ([]*model.Session, error)
+
+	// Revoke the user's session.
+	RevokeSession(userID, sessionID int64) error
+
+	// Revoke all user's sessions except one.
+	RevokeSessions(userID, exceptSessionID int64) ([]int64, error)
+
+	// Save TOTP secret which is not enabled yet.
+	SetTotpSecret(userID int64, secret string) error
+
+	// Get TOTP secret and whether it's enabled.
+	GetTotpSecret(userID int64) (secret string, enabled bool, err error)
+
+	// Enable TOTP and replace recovery codes.
+	EnableTotp(userID, counter int64, recoveryCodeHashes []string) error
+
+	// Save the last used TOTP time step, earlier steps are rejected.
+	UseTotpCounter(userID, counter int64) error
+
+	// Use the recovery code once.
+	UseRecoveryCode(userID int64, codeHash string) error
+
+	// Disable TOTP and remove recovery codes.
+	DisableTotp(userID int64) error
+
+	// Get user's profile and settings.
+	GetProfile(userID int64) (*model.Profile, error)
+
+	// Save user's profile and settings.
+	UpdateProfile(userID int64, profile *model.Profile) error
+
+	// Set the time of avatar upload, nil if avatar is deleted.
+	SetAvatarUpdatedAt(userID int64, updatedAt *time.Time) error
+
+	// Get started games of the user with moves.
+	GetGameRecords(userID int64) ([]*model.GameRecord, error)
+	GetFinishedGameRecord(gameID int64) (*model.GameRecord, error)
+
+	// Get chat messages written by the user.
+	GetUserMessages(userID int64) ([]model.Message, error)
+
+	// Get ranking changes of the user.
+	GetRatingHistory(userID int64) ([]model.RatingChange, error)
+
+	// Anonymise the user and remove personal data.
+	DeleteAccount(userID int64) error
+
+	// Get the system user which plays against users.
+	GetOrCreateBotUser(username string) (*model.User, error)
+
+	// Create started unrated game against the bot.
+	CreateBotGame(blackUserID, whiteUserID int64, botLevel int) (gameID int64, err error)
+
 	// Close
 	Close() error
 }
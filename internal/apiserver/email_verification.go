@@ -0,0 +1,68 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"github.com/renju24/backend/model"
+)
+
+const (
+	tokenPurposeVerifyEmail = "verify_email"
+
+	defaultEmailVerificationTTL = 24 * time.Hour
+)
+
+// sendVerificationEmail issues a new verification token and emails the link to user.
+func (apiServer *APIServer) sendVerificationEmail(user *model.User) error {
+	if user.Email == nil {
+		return apierror.ErrorEmailIsRequired
+	}
+	ttl := time.Duration(apiServer.config.EmailVerification.TokenTTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultEmailVerificationTTL
+	}
+	token, hash, err := apiServer.tokenSigner.New(tokenPurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if err = apiServer.db.CreateEmailVerificationToken(user.ID, *user.Email, hash, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+	return apiServer.mailer.Enqueue(*user.Email, apiServer.userMailLocale(user.ID), mailer.TemplateVerifyEmail, map[string]any{
+		"Username":  user.Username,
+		"URL":       apiServer.config.Mail.BaseURL + "/api/v1/verify_email?token=" + url.QueryEscape(token),
+		"ExpiresIn": ttl.String(),
+	})
+}
+
+// verifyEmail handles the link from verification email and redirects user to the web app.
+func verifyEmail(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		redirect := func(verified bool) {
+			result := "0"
+			if verified {
+				result = "1"
+			}
+			c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web+"?email_verified="+result)
+		}
+		hash, err := api.tokenSigner.Verify(tokenPurposeVerifyEmail, c.Query("token"))
+		if err != nil {
+			redirect(false)
+			return
+		}
+		if _, err = api.db.VerifyEmail(hash); err != nil {
+			if !errors.Is(err, apierror.ErrorInvalidToken) {
+				api.logger.Error().Err(err).Send()
+			}
+			redirect(false)
+			return
+		}
+		redirect(true)
+	}
+}
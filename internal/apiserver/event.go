@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/armantarkhanian/websocket"
 	"github.com/centrifugal/centrifuge"
+	pkggame "github.com/renju24/backend/pkg/game"
 )
 
 type Event interface {
@@ -29,6 +31,21 @@ func (apiServer *APIServer) PublishEvent(channel string, event Event) (centrifug
 	return res, nil
 }
 
+// sendToClient sends the event to the single connection as the asynchronous message, it's not published to channels.
+func (apiServer *APIServer) sendToClient(c *websocket.Client, event Event) {
+	msg, err := json.Marshal(map[string]any{
+		"event_type": event.EventType(),
+		"data":       event,
+	})
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	if err = c.Send(msg); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+}
+
 type EventGameInvitation struct {
 	GameID    int64     `json:"game_id"`
 	Inviter   string    `json:"inviter"`
@@ -61,6 +78,61 @@ type EventMove struct {
 	UserID      int64 `json:"user_id"`
 	XCoordinate int   `json:"x_coordinate"`
 	YCoordinate int   `json:"y_coordinate"`
+	// ForbiddenPoints are black's forbidden points after the move, they are omitted if there are none.
+	ForbiddenPoints []ForbiddenPoint `json:"forbidden_points,omitempty"`
+	// Fours and OpenThrees are made by the move, Forced is true if the opponent must respond to them.
+	Fours      []Threat `json:"fours,omitempty"`
+	OpenThrees []Threat `json:"open_threes,omitempty"`
+	Forced     bool     `json:"forced,omitempty"`
+}
+
+type Point struct {
+	XCoordinate int `json:"x_coordinate"`
+	YCoordinate int `json:"y_coordinate"`
+}
+
+// Threat is the row of stones and the points which complete it: to five for four, to open four for three.
+type Threat struct {
+	Stones []Point `json:"stones"`
+	Points []Point `json:"points"`
+}
+
+func newPoints(points []pkggame.Point) []Point {
+	result := make([]Point, 0, len(points))
+	for _, p := range points {
+		result = append(result, Point{XCoordinate: p.X, YCoordinate: p.Y})
+	}
+	return result
+}
+
+func newThreats(threats []pkggame.Threat) []Threat {
+	var result []Threat
+	for _, t := range threats {
+		result = append(result, Threat{
+			Stones: newPoints(t.Stones),
+			Points: newPoints(t.Points),
+		})
+	}
+	return result
+}
+
+// ForbiddenPoint is the point where black can't move, the reason is overline, double_three or double_four.
+type ForbiddenPoint struct {
+	XCoordinate int    `json:"x_coordinate"`
+	YCoordinate int    `json:"y_coordinate"`
+	Reason      string `json:"reason"`
+}
+
+func newForbiddenPoints(points []pkggame.ForbiddenPoint) []ForbiddenPoint {
+	result := make([]ForbiddenPoint, 0, len(points))
+	for _, p := range points {
+		result = append(result, ForbiddenPoint{
+			XCoordinate: p.X,
+			YCoordinate: p.Y,
+			Reason:      string(p.Reason),
+		})
+	}
+	return result
 }
 
 func (e *EventMove) EventType() string {
@@ -69,6 +141,8 @@ func (e *EventMove) EventType() string {
 
 type EventGameEndedWithWinner struct {
 	WinnerID int64 `json:"winner_id"`
+	// WinningLine is set if the game is won by five.
+	WinningLine []Point `json:"winning_line,omitempty"`
 }
 
 func (e *EventGameEndedWithWinner) EventType() string {
@@ -89,3 +163,78 @@ type EventUserLeftGame struct {
 func (e *EventUserLeftGame) EventType() string {
 	return "user_left_game"
 }
+
+type EventChatMessage struct {
+	MessageID int64     `json:"message_id"`
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Text      string    `json:"text"`
+	SentAt    time.Time `json:"sent_at"`
+}
+
+func (e *EventChatMessage) EventType() string {
+	return "chat_message"
+}
+
+type EventChatMessageDeleted struct {
+	MessageID int64 `json:"message_id"`
+}
+
+func (e *EventChatMessageDeleted) EventType() string {
+	return "chat_message_deleted"
+}
+
+type EventUserMuted struct {
+	MutedUntil time.Time `json:"muted_until"`
+	Reason     string    `json:"reason"`
+}
+
+func (e *EventUserMuted) EventType() string {
+	return "user_muted"
+}
+
+type EventFriendRequest struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (e *EventFriendRequest) EventType() string {
+	return "friend_request"
+}
+
+type EventFriendRequestAccepted struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (e *EventFriendRequestAccepted) EventType() string {
+	return "friend_request_accepted"
+}
+
+type EventFriendOnline struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (e *EventFriendOnline) EventType() string {
+	return "friend_online"
+}
+
+type EventFriendOffline struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+func (e *EventFriendOffline) EventType() string {
+	return "friend_offline"
+}
+
+// EventRateLimited precedes ErrorRateLimited reply, RetryAfter is the number of seconds to wait.
+type EventRateLimited struct {
+	Method     string `json:"method"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+func (e *EventRateLimited) EventType() string {
+	return "rate_limited"
+}
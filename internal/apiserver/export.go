@@ -0,0 +1,142 @@
+package apiserver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/config"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+	"github.com/renju24/backend/model"
+)
+
+var defaultDataExports = config.RateLimitConfig{Requests: 3, Period: 3600}
+
+// exportProfile is profile.json of the data export.
+type exportProfile struct {
+	ID               int64            `json:"id"`
+	Username         string           `json:"username"`
+	Email            *string          `json:"email"`
+	EmailVerified    bool             `json:"email_verified"`
+	Ranking          int              `json:"ranking"`
+	TwoFactorEnabled bool             `json:"two_factor_enabled"`
+	Profile          profileResponse  `json:"profile"`
+	LinkedAccounts   []oauth.Service  `json:"linked_accounts"`
+	Sessions         []*model.Session `json:"sessions"`
+}
+
+// exportData returns ZIP with the user's personal data:
+//
+//	profile.json        account, profile, settings, linked accounts and sessions
+//	games/<id>.txt      games in renju notation
+//	chat.json           chat messages
+//	rating_history.json ranking changes
+func exportData(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := api.authorizedUserID(api.requestToken(c))
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, &apierror.Error{
+				Error: apierror.ErrorUnauthorized,
+			})
+			return
+		}
+		if ok, retryAfter := api.exportLimiter.Reserve(strconv.FormatInt(userID, 10)); !ok {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
+		data, err := api.exportUserData(userID)
+		if err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		filename := fmt.Sprintf("renju24-%d-%s.zip", userID, time.Now().Format("20060102"))
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Header("Cache-Control", "no-store")
+		c.Data(http.StatusOK, "application/zip", data)
+	}
+}
+
+func (api *APIServer) exportUserData(userID int64) ([]byte, error) {
+	user, err := api.db.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := api.db.GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+	services, err := api.db.GetOauthServices(userID)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := api.db.GetActiveSessions(userID)
+	if err != nil {
+		return nil, err
+	}
+	games, err := api.db.GetGameRecords(userID)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := api.db.GetUserMessages(userID)
+	if err != nil {
+		return nil, err
+	}
+	ratingHistory, err := api.db.GetRatingHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if err = writeZipJSON(archive, "profile.json", &exportProfile{
+		ID:               user.ID,
+		Username:         user.Username,
+		Email:            user.Email,
+		EmailVerified:    user.EmailVerified,
+		Ranking:          user.Ranking,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		Profile:          newProfileResponse(user.ID, profile, true),
+		LinkedAccounts:   services,
+		Sessions:         sessions,
+	}); err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		w, err := archive.Create("games/" + strconv.FormatInt(game.ID, 10) + ".txt")
+		if err != nil {
+			return nil, err
+		}
+		if _, err = w.Write([]byte(newGameRecord(game).String())); err != nil {
+			return nil, err
+		}
+	}
+	if err = writeZipJSON(archive, "chat.json", messages); err != nil {
+		return nil, err
+	}
+	if err = writeZipJSON(archive, "rating_history.json", ratingHistory); err != nil {
+		return nil, err
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeZipJSON(archive *zip.Writer, name string, v any) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,77 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+	pkggame "github.com/renju24/backend/pkg/game"
+	"github.com/renju24/backend/pkg/notation"
+)
+
+// newGameRecord converts the game to the RIF record, colors of moves alternate from black.
+func newGameRecord(game *model.GameRecord) *notation.Record {
+	record := &notation.Record{
+		Site:   "Renju24",
+		Date:   "????.??.??",
+		Black:  game.BlackUsername,
+		White:  game.WhiteUsername,
+		Rule:   notation.RuleRIF,
+		Result: notation.Unfinished,
+		Tags: []notation.Tag{
+			{Name: "Game", Value: strconv.FormatInt(game.ID, 10)},
+			{Name: "Rated", Value: strconv.FormatBool(game.Rated)},
+		},
+	}
+	if game.StartedAt != nil {
+		record.Date = game.StartedAt.Format(notation.DateFormat)
+	}
+	if game.WinnerUsername != nil {
+		record.Result = notation.WhiteWins
+		if *game.WinnerUsername == game.BlackUsername {
+			record.Result = notation.BlackWins
+		}
+	}
+	for i, move := range game.Moves {
+		color := pkggame.Black
+		if i%2 == 1 {
+			color = pkggame.White
+		}
+		record.Moves = append(record.Moves, pkggame.NewMove(move.XCoordinate, move.YCoordinate, color))
+	}
+	return record
+}
+
+// downloadGameRecord returns the finished game in RIF notation, finished games are public like the game history.
+func downloadGameRecord(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		gameID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		game, err := api.db.GetFinishedGameRecord(gameID)
+		if err != nil {
+			if errors.Is(err, apierror.ErrorGameNotFound) {
+				c.JSON(http.StatusNotFound, &apierror.Error{
+					Error: apierror.ErrorGameNotFound,
+				})
+				return
+			}
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		filename := fmt.Sprintf("renju24-game-%d.txt", game.ID)
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(newGameRecord(game).String()))
+	}
+}
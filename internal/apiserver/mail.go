@@ -0,0 +1,106 @@
+package apiserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
+)
+
+func newMailer(cfg config.MailConfig, outbox mailer.Outbox, logger *zerolog.Logger) (*mailer.Mailer, error) {
+	templates, err := mailer.NewTemplates(cfg.DefaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	var transport mailer.Transport
+	switch cfg.Transport {
+	case "smtp":
+		transport = &mailer.SMTPTransport{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+		}
+	case "file":
+		transport = &mailer.FileTransport{
+			Dir:  cfg.Dir,
+			From: cfg.From,
+		}
+	default:
+		transport = &mailer.LogTransport{
+			Logger: logger,
+		}
+	}
+	return mailer.New(
+		transport,
+		outbox,
+		templates,
+		logger,
+		cfg.MaxAttempts,
+		time.Duration(cfg.PollInterval)*time.Second,
+	), nil
+}
+
+// mailLocale returns the template locale for user's preferred language, e.g. "ru" for "ru-RU".
+// The default locale is used if user has not chosen the language.
+func (apiServer *APIServer) mailLocale(lang *string) string {
+	if lang == nil {
+		return apiServer.config.Mail.DefaultLocale
+	}
+	tag, err := language.Parse(*lang)
+	if err != nil {
+		return apiServer.config.Mail.DefaultLocale
+	}
+	base, _ := tag.Base()
+	return base.String()
+}
+
+// userMailLocale reads user's preferred language from the profile settings.
+func (apiServer *APIServer) userMailLocale(userID int64) string {
+	profile, err := apiServer.db.GetProfile(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return apiServer.config.Mail.DefaultLocale
+	}
+	return apiServer.mailLocale(profile.Settings.Language)
+}
+
+// runNotificationDigest periodically emails users about notifications they have not read.
+func (apiServer *APIServer) runNotificationDigest(ctx context.Context) {
+	interval := time.Duration(apiServer.config.Mail.DigestInterval) * time.Second
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		olderThan := time.Now().Add(-interval)
+		digests, err := apiServer.db.NotificationDigests(olderThan)
+		if err != nil {
+			apiServer.logger.Error().Err(err).Send()
+			continue
+		}
+		for _, digest := range digests {
+			if err = apiServer.mailer.Enqueue(digest.Email, apiServer.mailLocale(digest.Language), mailer.TemplateNotificationDigest, map[string]any{
+				"Username":    digest.Username,
+				"UnreadCount": digest.UnreadCount,
+				"URL":         apiServer.config.Mail.BaseURL,
+			}); err != nil {
+				apiServer.logger.Error().Err(err).Send()
+				continue
+			}
+			if err = apiServer.db.MarkNotificationsDigested(digest.UserID, olderThan); err != nil {
+				apiServer.logger.Error().Err(err).Send()
+			}
+		}
+	}
+}
@@ -1,13 +1,17 @@
 package apiserver
 
 import (
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/ratelimit"
 )
 
 func sameSiteMiddleware(a *APIServer) gin.HandlerFunc {
@@ -62,3 +66,32 @@ func loggerMiddleware(a *APIServer) gin.HandlerFunc {
 			Str("endpoint", path).Send()
 	}
 }
+
+// rateLimitMiddleware takes a token from the bucket of the request key and aborts with 429 if it's empty.
+// Requests with empty key are not limited.
+func rateLimitMiddleware(a *APIServer, limiter *ratelimit.Limiter, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		k := key(c)
+		if k == "" {
+			return
+		}
+		if ok, retryAfter := limiter.Reserve(k); !ok {
+			a.logger.Warn().Str("clientIP", c.ClientIP()).Str("endpoint", c.Request.URL.Path).Msg("rate limit exceeded")
+			abortTooManyRequests(c, retryAfter)
+		}
+	}
+}
+
+func abortTooManyRequests(c *gin.Context, retryAfter time.Duration) {
+	seconds := retryAfterSeconds(retryAfter)
+	c.Header("Retry-After", strconv.Itoa(seconds))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, &apierror.Error{
+		Error:      apierror.ErrorTooManyRequests,
+		RetryAfter: seconds,
+	})
+}
+
+// retryAfterSeconds rounds the wait up, so the client doesn't retry too early.
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
+}
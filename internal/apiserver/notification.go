@@ -0,0 +1,30 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// Notify stores the event in user's notification inbox and publishes it into user's channel,
+// so an offline user will see the event next time he comes online.
+func (apiServer *APIServer) Notify(userID int64, event Event) (centrifuge.PublishResult, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return centrifuge.PublishResult{}, err
+	}
+	notification, err := apiServer.db.CreateNotification(userID, event.EventType(), data)
+	if err != nil {
+		return centrifuge.PublishResult{}, err
+	}
+	msg, err := json.Marshal(map[string]any{
+		"event_type":      event.EventType(),
+		"data":            event,
+		"notification_id": notification.ID,
+	})
+	if err != nil {
+		return centrifuge.PublishResult{}, err
+	}
+	return apiServer.centrifugeNode.Publish(fmt.Sprintf("user_%d", userID), msg)
+}
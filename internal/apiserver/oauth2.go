@@ -1,17 +1,17 @@
 package apiserver
 
 import (
+	"errors"
 	"net/http"
-	"path/filepath"
 	"strconv"
 	"strings"
 
-	"github.com/armantarkhanian/jwt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/renju24/backend/internal/pkg/apierror"
 	oauth "github.com/renju24/backend/internal/pkg/oauth2"
 )
 
-// TODO: hard code.
 func oauth2Services(api *APIServer) gin.HandlerFunc {
 	type service struct {
 		Name  oauth.Service `json:"name"`
@@ -21,7 +21,7 @@ func oauth2Services(api *APIServer) gin.HandlerFunc {
 	type response []service
 	host := "http://localhost" + api.addr
 	if api.runMode == "prod" {
-		host = filepath.Join("https://", api.config.Server.Token.Cookie.Domain)
+		host = "https://" + api.config.Server.Token.Cookie.Domain
 	}
 	return func(c *gin.Context) {
 		platform, err := oauth.ParsePlatform(c.Param("platform"))
@@ -29,57 +29,30 @@ func oauth2Services(api *APIServer) gin.HandlerFunc {
 			c.AbortWithStatus(http.StatusNotFound)
 			return
 		}
-		var (
-			googleLogo = filepath.Join(host, "assets/images/logos/google.svg")
-			yandexLogo = filepath.Join(host, "assets/images/logos/yandex.svg")
-			vkLogo     = filepath.Join(host, "assets/images/logos/vk.svg")
-		)
-		switch platform {
-		case oauth.Web:
-			c.JSON(http.StatusOK, &response{
-				{
-					Name:  oauth.Google,
-					Image: googleLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Google.Callbacks.Web, "/callback"),
-				},
-				{
-					Name:  oauth.Yandex,
-					Image: yandexLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Yandex.Callbacks.Web, "/callback"),
-				},
-				{
-					Name:  oauth.VK,
-					Image: vkLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Yandex.Callbacks.Android, "/callback"),
-				},
-			})
-		case oauth.Android:
-			c.JSON(http.StatusOK, &response{
-				{
-					Name:  oauth.Google,
-					Image: googleLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Google.Callbacks.Android, "/callback"),
-				},
-				{
-					Name:  oauth.Yandex,
-					Image: yandexLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Yandex.Callbacks.Android, "/callback"),
-				},
-				{
-					Name:  oauth.VK,
-					Image: vkLogo,
-					URL:   strings.TrimSuffix(api.config.Oauth2.Yandex.Callbacks.Android, "/callback"),
-				},
+		resp := response{}
+		for _, provider := range api.oauthProviders.Providers() {
+			// Provider is not configured for the platform.
+			callback := provider.Callback(platform)
+			if callback == "" {
+				continue
+			}
+			image := provider.Logo
+			if strings.HasPrefix(image, "/") {
+				image = host + image
+			}
+			resp = append(resp, service{
+				Name:  provider.Name,
+				Image: image,
+				URL:   strings.TrimSuffix(callback, "/callback"),
 			})
-		default:
-			c.AbortWithStatus(http.StatusNotFound)
 		}
+		c.JSON(http.StatusOK, &resp)
 	}
 }
 
 func oauth2Login(api *APIServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		service, err := oauth.ParseService(c.Param("service"))
+		provider, err := api.oauthProviders.Provider(c.Param("service"))
 		if err != nil {
 			c.AbortWithStatus(http.StatusNotFound)
 			return
@@ -91,26 +64,43 @@ func oauth2Login(api *APIServer) gin.HandlerFunc {
 		}
 		if platform == oauth.Web {
 			cookieValue, _ := c.Cookie(api.config.Server.Token.Cookie.Name)
-			var payload jwt.Payload
-			if err = api.jwt.Decode(cookieValue, &payload); err == nil {
+			if api.authorizedUserID(cookieValue) != 0 {
 				// If user is already authorized, then redirect him to the main page.
 				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
 				return
 			}
 		}
-		oauthCfg, err := oauth.OauthConfig(api.config.Oauth2, service, platform)
+		oauthCfg, err := provider.Config(platform)
 		if err != nil {
 			c.AbortWithStatus(http.StatusNotFound)
 			return
 		}
-		authPage := oauthCfg.AuthCodeURL("c5ae9e1e63e14761a2933110db39fb3a")
+		state, opts, err := api.newOauthState(c, platform, 0)
+		if err != nil {
+			api.oauthStateFailed(c, platform, err)
+			return
+		}
+		authPage := oauthCfg.AuthCodeURL(state, opts...)
 		c.Redirect(http.StatusFound, authPage)
 	}
 }
 
+// oauthStateFailed redirects to the deep link with the error when the flow can't be started.
+func (api *APIServer) oauthStateFailed(c *gin.Context, platform oauth.Platform, err error) {
+	if errors.Is(err, errInvalidOauthState) {
+		c.Redirect(http.StatusFound, withQuery(api.oauthDeepLink(platform), "error", strconv.FormatUint(uint64(apierror.ErrorBadRequest.Code), 10)))
+		return
+	}
+	api.logger.Error().Err(err).Send()
+	c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
+}
+
+// oauth2Callback handles the authorization response.
+// Web flows exchange the code right here. Mobile apps receive the code and the state in the deep link
+// and redeem them with the PKCE verifier by oauth2Token, so the code intercepted from the deep link is useless.
 func oauth2Callback(api *APIServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		service, err := oauth.ParseService(c.Param("service"))
+		provider, err := api.oauthProviders.Provider(c.Param("service"))
 		if err != nil {
 			c.AbortWithStatus(http.StatusNotFound)
 			return
@@ -122,72 +112,169 @@ func oauth2Callback(api *APIServer) gin.HandlerFunc {
 		}
 		code := c.Request.FormValue("code")
 		if code == "" {
-			switch platform {
-			case oauth.Android:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Android)
-			default:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
+			c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
+			return
+		}
+		if platform != oauth.Web {
+			state := c.Request.FormValue("state")
+			if _, err = api.decodeOauthState(state); err != nil {
+				api.logger.Warn().Err(err).Str("ip", c.ClientIP()).Send()
+				c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
+				return
 			}
+			deepLink := withQuery(api.oauthDeepLink(platform), "code", code)
+			c.Redirect(http.StatusFound, withQuery(deepLink, "state", state))
 			return
 		}
-		var oauthUser *oauth.User
-		switch service {
-		case oauth.Google:
-			oauthUser, err = oauth.GoogleOauth(api.config.Oauth2, code, service, platform)
-		case oauth.Yandex:
-			oauthUser, err = oauth.YandexOauth(api.config.Oauth2, code, service, platform)
-		case oauth.VK:
-			oauthUser, err = oauth.VKOauth(api.config.Oauth2, code, service, platform)
+		// Forged or replayed authorization responses are rejected.
+		linkUserID, err := api.verifyWebOauthState(c)
+		if err != nil {
+			api.logger.Warn().Err(err).Str("ip", c.ClientIP()).Send()
+			c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
+			return
 		}
+		oauthUser, err := provider.User(c.Request.Context(), code, platform)
 		if err != nil {
 			api.logger.Error().Err(err).Send()
-			switch platform {
-			case oauth.Android:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Android)
-			default:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
-			}
+			c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
 			return
 		}
-		user, err := api.db.CreateUserOauth(oauthUser.Username, oauthUser.Email, oauthUser.ID, service)
+		resp, err := api.finishOauth(c, provider.Name, oauthUser, linkUserID)
 		if err != nil {
-			api.logger.Error().Err(err).Send()
-			switch platform {
-			case oauth.Android:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Android)
-			default:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
+			apiErr := apierror.ErrorInternal
+			if !errors.As(err, &apiErr) {
+				api.logger.Error().Err(err).Send()
 			}
+			c.Redirect(http.StatusFound, withQuery(api.oauthDeepLink(platform), "error", strconv.FormatUint(uint64(apiErr.Code), 10)))
 			return
 		}
-		jwtToken, err := api.jwt.Encode(jwt.Payload{
-			Subject:        strconv.FormatInt(user.ID, 10),
-			ExpirationTime: int64(api.config.Server.Token.Cookie.MaxAge),
-		})
+		switch {
+		case resp.Linked != "":
+			c.Redirect(http.StatusFound, withQuery(api.oauthDeepLink(platform), "linked", string(resp.Linked)))
+		case resp.LinkToken != "":
+			c.Redirect(http.StatusFound, withQuery(api.oauthDeepLink(platform), "link_token", resp.LinkToken))
+		case resp.TwoFactorRequired:
+			c.Redirect(http.StatusFound, withQuery(api.oauthDeepLink(platform), "challenge_token", resp.ChallengeToken))
+		default:
+			api.setAuthCookies(c, resp.authTokens)
+			c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
+		}
+	}
+}
+
+type oauthTokenRequest struct {
+	Code         string `json:"code"`
+	State        string `json:"state"`
+	CodeVerifier string `json:"code_verifier"`
+}
+
+// oauthResponse is the outcome of OAuth2 sign in, only one of the fields is set.
+type oauthResponse struct {
+	*authTokens
+	// TwoFactorRequired means the challenge token must be sent with TOTP or recovery code to get the tokens.
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	// LinkToken is returned if the OAuth2 email belongs to the existing account, see signinRequest.
+	LinkToken string `json:"link_token,omitempty"`
+	// Linked is the service linked to the signed in user's account.
+	Linked oauth.Service `json:"linked,omitempty"`
+}
+
+// oauth2Token redeems the code of the mobile flow with the PKCE verifier which only the app knows.
+// Linking the service also requires the access token of the user who started the flow.
+func oauth2Token(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, err := api.oauthProviders.Provider(c.Param("service"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		platform, err := oauth.ParsePlatform(c.Param("platform"))
+		if err != nil || platform == oauth.Web {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		var req oauthTokenRequest
+		if err = c.ShouldBindBodyWith(&req, binding.JSON); err != nil || req.Code == "" {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		state, err := api.verifyMobileOauthState(req.State, req.CodeVerifier)
+		if err != nil {
+			api.logger.Warn().Err(err).Str("ip", c.ClientIP()).Send()
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
+			return
+		}
+		if state.LinkUserID != 0 && api.authorizedUserID(api.requestToken(c)) != state.LinkUserID {
+			c.JSON(http.StatusUnauthorized, &apierror.Error{
+				Error: apierror.ErrorUnauthorized,
+			})
+			return
+		}
+		oauthUser, err := provider.User(c.Request.Context(), req.Code, platform, oauth.VerifierOption(req.CodeVerifier))
 		if err != nil {
 			api.logger.Error().Err(err).Send()
-			switch platform {
-			case oauth.Android:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Android)
-			default:
-				c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
-			}
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
 			return
 		}
-		if platform == oauth.Android {
-			deepLink := api.config.Oauth2.DeepLinks.Android + "?token=" + jwtToken
-			c.Redirect(http.StatusFound, deepLink)
+		resp, err := api.finishOauth(c, provider.Name, oauthUser, state.LinkUserID)
+		if err != nil {
+			apiErr := apierror.ErrorInternal
+			if !errors.As(err, &apiErr) {
+				api.logger.Error().Err(err).Send()
+				c.JSON(http.StatusInternalServerError, &apierror.Error{
+					Error: apiErr,
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apiErr,
+			})
 			return
 		}
-		c.SetCookie(
-			api.config.Server.Token.Cookie.Name,
-			jwtToken,
-			api.config.Server.Token.Cookie.MaxAge,
-			api.config.Server.Token.Cookie.Path,
-			api.config.Server.Token.Cookie.Domain,
-			api.config.Server.Token.Cookie.Secure,
-			api.config.Server.Token.Cookie.HttpOnly,
-		)
-		c.Redirect(http.StatusFound, api.config.Oauth2.DeepLinks.Web)
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// finishOauth links the service to the account of user who started the flow,
+// or signs in user of the OAuth2 account creating him if needed.
+func (api *APIServer) finishOauth(c *gin.Context, service oauth.Service, oauthUser *oauth.User, linkUserID int64) (*oauthResponse, error) {
+	// Signed in user links the service to the account.
+	if linkUserID != 0 {
+		if err := api.db.LinkOauth(linkUserID, oauthUser.ID, service); err != nil {
+			return nil, err
+		}
+		return &oauthResponse{Linked: service}, nil
+	}
+	user, err := api.db.CreateUserOauth(oauthUser.Username, oauthUser.Email, oauthUser.ID, service)
+	if err != nil {
+		// The email belongs to the existing account, user can sign in to it and link the service.
+		if errors.Is(err, apierror.ErrorEmailIsTaken) && oauthUser.Email != nil {
+			linkToken, err := api.newOauthLinkToken(service, oauthUser)
+			if err != nil {
+				return nil, err
+			}
+			return &oauthResponse{LinkToken: linkToken}, nil
+		}
+		return nil, err
+	}
+	// Users with two-factor authentication finish sign in with the challenge token.
+	if user.TwoFactorEnabled {
+		challenge, err := api.newTwoFactorChallenge(user.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		return &oauthResponse{TwoFactorRequired: true, ChallengeToken: challenge}, nil
+	}
+	tokens, err := api.newSession(c, user.ID)
+	if err != nil {
+		return nil, err
 	}
+	return &oauthResponse{authTokens: tokens}, nil
 }
@@ -0,0 +1,118 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/armantarkhanian/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+)
+
+const oauthLinkTokenTTL = 10 * time.Minute
+
+// oauthLinkToken is given to user whose OAuth2 email belongs to the existing account.
+// User links the OAuth2 account by signing in to the existing one with the token.
+type oauthLinkToken struct {
+	jwt.Payload
+	Service oauth.Service `json:"service"`
+	OauthID string        `json:"oauth_id"`
+	Email   string        `json:"email"`
+}
+
+func (api *APIServer) oauthDeepLink(platform oauth.Platform) string {
+	switch platform {
+	case oauth.Android:
+		return api.config.Oauth2.DeepLinks.Android
+	case oauth.IOS:
+		return api.config.Oauth2.DeepLinks.IOS
+	default:
+		return api.config.Oauth2.DeepLinks.Web
+	}
+}
+
+// withQuery appends query parameter to the deep link.
+func withQuery(deepLink, key, value string) string {
+	separator := "?"
+	if strings.Contains(deepLink, "?") {
+		separator = "&"
+	}
+	return deepLink + separator + key + "=" + url.QueryEscape(value)
+}
+
+// authorizedUserID returns ID of user authorized with the access token, or zero.
+func (api *APIServer) authorizedUserID(token string) int64 {
+	auth, err := api.authenticate(token)
+	if err != nil {
+		if !errors.Is(err, apierror.ErrorInvalidToken) {
+			api.logger.Error().Err(err).Send()
+		}
+		return 0
+	}
+	return auth.User.ID
+}
+
+func (api *APIServer) newOauthLinkToken(service oauth.Service, oauthUser *oauth.User) (string, error) {
+	return api.jwt.Encode(oauthLinkToken{
+		Payload: jwt.Payload{
+			ExpirationTime: time.Now().Add(oauthLinkTokenTTL).Unix(),
+		},
+		Service: service,
+		OauthID: oauthUser.ID,
+		Email:   *oauthUser.Email,
+	})
+}
+
+// linkOauthWithToken links the OAuth2 account from the link token to user who has just signed in.
+// The emails must match, otherwise anyone could link his OAuth2 account to a victim's account by sending the token.
+func (api *APIServer) linkOauthWithToken(userID int64, email *string, token string) error {
+	var linkToken oauthLinkToken
+	if err := api.jwt.Decode(token, &linkToken); err != nil || linkToken.OauthID == "" {
+		return apierror.ErrorInvalidToken
+	}
+	if linkToken.ExpirationTime < time.Now().Unix() {
+		return apierror.ErrorInvalidToken
+	}
+	if email == nil || !strings.EqualFold(*email, linkToken.Email) {
+		return apierror.ErrorInvalidToken
+	}
+	return api.db.LinkOauth(userID, linkToken.OauthID, linkToken.Service)
+}
+
+// oauth2Link redirects signed in user to the OAuth2 provider to link the service to the account.
+// Web clients are authorized with cookie. Mobile clients send the access token in the header and code_challenge
+// in query, then open the returned location in the browser. Tokens in URLs would leak to logs and Referer.
+func oauth2Link(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provider, err := api.oauthProviders.Provider(c.Param("service"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		platform, err := oauth.ParsePlatform(c.Param("platform"))
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		userID := api.authorizedUserID(api.requestToken(c))
+		if userID == 0 {
+			c.Redirect(http.StatusFound, api.oauthDeepLink(platform))
+			return
+		}
+		oauthCfg, err := provider.Config(platform)
+		if err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		state, opts, err := api.newOauthState(c, platform, userID)
+		if err != nil {
+			api.oauthStateFailed(c, platform, err)
+			return
+		}
+		c.Redirect(http.StatusFound, oauthCfg.AuthCodeURL(state, opts...))
+	}
+}
@@ -0,0 +1,132 @@
+package apiserver
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/armantarkhanian/jwt"
+	"github.com/gin-gonic/gin"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oauthStateTTL    = 10 * time.Minute
+	oauthStateCookie = "oauth_state"
+	oauthStatePath   = "/api/v1/oauth2"
+)
+
+var errInvalidOauthState = errors.New("invalid oauth state")
+
+// codeChallengeRegexp matches S256 code challenge: base64url of SHA-256 without padding (RFC 7636).
+var codeChallengeRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`)
+
+// oauthState is passed through OAuth2 provider. It's signed, so LinkUserID and Challenge can't be forged.
+// Web flows are bound to the browser by the nonce cookie.
+// Mobile flows are bound to the app by PKCE: the app keeps the verifier and sends only its challenge,
+// so the code from the deep link is useless without the verifier.
+// It has no subject, so it can't be used as an auth token.
+type oauthState struct {
+	jwt.Payload
+	Nonce string `json:"nonce,omitempty"`
+	// Challenge is S256 code challenge of mobile flow.
+	Challenge string `json:"challenge,omitempty"`
+	// LinkUserID is set when signed in user links the service to the account.
+	LinkUserID int64 `json:"link_user_id,omitempty"`
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newOauthState returns the state and the options for the authorization URL.
+// Mobile apps pass code_challenge in the query, errInvalidOauthState is returned if it's missing or malformed.
+func (api *APIServer) newOauthState(c *gin.Context, platform oauth.Platform, linkUserID int64) (string, []oauth2.AuthCodeOption, error) {
+	state := oauthState{
+		Payload: jwt.Payload{
+			ExpirationTime: time.Now().Add(oauthStateTTL).Unix(),
+		},
+		LinkUserID: linkUserID,
+	}
+	if platform != oauth.Web {
+		state.Challenge = c.Query("code_challenge")
+		if !codeChallengeRegexp.MatchString(state.Challenge) {
+			return "", nil, errInvalidOauthState
+		}
+		encoded, err := api.jwt.Encode(state)
+		if err != nil {
+			return "", nil, err
+		}
+		return encoded, oauth.ChallengeOptions(state.Challenge), nil
+	}
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", nil, err
+	}
+	state.Nonce = nonce
+	encoded, err := api.jwt.Encode(state)
+	if err != nil {
+		return "", nil, err
+	}
+	// Lax is required, the callback is a cross-site redirect from the provider.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		oauthStateCookie,
+		nonce,
+		int(oauthStateTTL.Seconds()),
+		oauthStatePath,
+		api.config.Server.Token.Cookie.Domain,
+		api.config.Server.Token.Cookie.Secure,
+		true,
+	)
+	return encoded, nil, nil
+}
+
+// decodeOauthState checks the signature and the expiration of the state.
+func (api *APIServer) decodeOauthState(raw string) (*oauthState, error) {
+	var state oauthState
+	if err := api.jwt.Decode(raw, &state); err != nil {
+		return nil, errInvalidOauthState
+	}
+	if state.ExpirationTime < time.Now().Unix() {
+		return nil, errInvalidOauthState
+	}
+	return &state, nil
+}
+
+// verifyWebOauthState checks the state of the web authorization response against the nonce cookie.
+func (api *APIServer) verifyWebOauthState(c *gin.Context) (linkUserID int64, err error) {
+	state, err := api.decodeOauthState(c.Request.FormValue("state"))
+	if err != nil || state.Nonce == "" {
+		return 0, errInvalidOauthState
+	}
+	cookieNonce, _ := c.Cookie(oauthStateCookie)
+	// The state cookie is single-use.
+	c.SetCookie(oauthStateCookie, "", -1, oauthStatePath, api.config.Server.Token.Cookie.Domain, api.config.Server.Token.Cookie.Secure, true)
+	if subtle.ConstantTimeCompare([]byte(cookieNonce), []byte(state.Nonce)) != 1 {
+		return 0, errInvalidOauthState
+	}
+	return state.LinkUserID, nil
+}
+
+// verifyMobileOauthState checks that the verifier sent by the app matches the challenge of the flow.
+// The provider checks it once more on the code exchange, and the code can be exchanged only once.
+func (api *APIServer) verifyMobileOauthState(raw, verifier string) (*oauthState, error) {
+	state, err := api.decodeOauthState(raw)
+	if err != nil || state.Challenge == "" {
+		return nil, errInvalidOauthState
+	}
+	if subtle.ConstantTimeCompare([]byte(oauth.S256Challenge(verifier)), []byte(state.Challenge)) != 1 {
+		return nil, errInvalidOauthState
+	}
+	return state, nil
+}
@@ -0,0 +1,302 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/armantarkhanian/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/config"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+	"github.com/renju24/backend/internal/pkg/securetoken"
+	"github.com/renju24/backend/model"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOauthCode        = "test-code"
+	testOauthAccessToken = "test-access-token"
+	testIOSCallback      = "https://renju24.com/api/v1/oauth2/ios/github/callback"
+)
+
+// fakeGithub is a local OAuth2 server with GitHub-like user API which requires PKCE verifier.
+func fakeGithub(t *testing.T, verifier string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		// Handlers run in the server's goroutines, so the mismatch fails the token exchange instead of the test.
+		if r.ParseForm() != nil || r.Form.Get("code") != testOauthCode || r.Form.Get("code_verifier") != verifier ||
+			r.Form.Get("redirect_uri") != testIOSCallback {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": testOauthAccessToken,
+			"token_type":   "bearer",
+		})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testOauthAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"id":    583231,
+			"login": "octocat",
+			"email": "octocat@example.com",
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// oauthTestDB stores users created by OAuth2 sign in, other methods aren't used by the flow.
+type oauthTestDB struct {
+	Database
+	users    map[string]*model.User
+	sessions []int64
+}
+
+func (db *oauthTestDB) CreateUserOauth(username string, email *string, oauthID string, service oauth.Service) (*model.User, error) {
+	key := string(service) + ":" + oauthID
+	if user, ok := db.users[key]; ok {
+		return user, nil
+	}
+	user := &model.User{ID: int64(len(db.users) + 1), Username: username, Email: email}
+	db.users[key] = user
+	return user, nil
+}
+
+func (db *oauthTestDB) CreateSession(userID int64, tokenHash, userAgent, ip string, expiresAt time.Time) (int64, error) {
+	db.sessions = append(db.sessions, userID)
+	return int64(len(db.sessions)), nil
+}
+
+func (db *oauthTestDB) GetSession(sessionID int64) (*model.Session, error) {
+	if sessionID < 1 || sessionID > int64(len(db.sessions)) {
+		return nil, apierror.ErrorSessionNotFound
+	}
+	return &model.Session{ID: sessionID, UserID: db.sessions[sessionID-1], ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (db *oauthTestDB) GetUserByID(userID int64) (*model.User, error) {
+	for _, user := range db.users {
+		if user.ID == userID {
+			return user, nil
+		}
+	}
+	return nil, apierror.ErrorUserNotFound
+}
+
+func newOauthTestServer(t *testing.T, githubURL string) (*APIServer, *gin.Engine, *oauthTestDB) {
+	gin.SetMode(gin.TestMode)
+	var cfg config.Config
+	cfg.Server.Token.SigningKey = "test-signing-key-of-at-least-32-chars"
+	cfg.Server.Token.Header.Name = "Authorization"
+	cfg.Oauth2 = config.OauthConfig{
+		DeepLinks: config.OauthRedirects{
+			Web: "https://renju24.com",
+			IOS: "renju24://oauth",
+		},
+		Github: config.OauthProviderConfig{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			Callbacks: config.OauthRedirects{
+				Web: "https://renju24.com/api/v1/oauth2/web/github/callback",
+				IOS: testIOSCallback,
+			},
+			API:      githubURL + "/user",
+			AuthURL:  githubURL + "/login/oauth/authorize",
+			TokenURL: githubURL + "/login/oauth/access_token",
+		},
+	}
+	jwtEncodeDecoder, err := jwt.New(cfg.Server.Token.SigningKey)
+	require.NoError(t, err)
+	providers, err := oauth.NewRegistry(cfg.Oauth2)
+	require.NoError(t, err)
+	logger := zerolog.Nop()
+	db := &oauthTestDB{users: map[string]*model.User{}}
+	api := &APIServer{
+		logger:         &logger,
+		config:         &cfg,
+		jwt:            jwtEncodeDecoder,
+		tokenSigner:    securetoken.NewSigner(cfg.Server.Token.SigningKey),
+		oauthProviders: providers,
+		db:             db,
+	}
+	router := gin.New()
+	router.GET("/api/v1/oauth2/:platform/services", oauth2Services(api))
+	router.GET("/api/v1/oauth2/:platform/:service", oauth2Login(api))
+	router.GET("/api/v1/oauth2/:platform/:service/callback", oauth2Callback(api))
+	router.POST("/api/v1/oauth2/:platform/:service/token", oauth2Token(api))
+	router.GET("/api/v1/oauth2/:platform/:service/link", oauth2Link(api))
+	return api, router, db
+}
+
+func serve(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	return serveWithToken(router, method, target, body, "")
+}
+
+func serveWithToken(router *gin.Engine, method, target, body, token string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func redirectLocation(t *testing.T, w *httptest.ResponseRecorder) *url.URL {
+	require.Equal(t, http.StatusFound, w.Code)
+	location, err := url.Parse(w.Header().Get("Location"))
+	require.NoError(t, err)
+	return location
+}
+
+func TestOauth2IOSGithub(t *testing.T) {
+	verifier, err := oauth.NewVerifier()
+	require.NoError(t, err)
+	github := fakeGithub(t, verifier)
+	_, router, db := newOauthTestServer(t, github.URL)
+
+	// The app opens the authorization page with the challenge of its verifier.
+	w := serve(router, http.MethodGet, "/api/v1/oauth2/ios/github?code_challenge="+oauth.S256Challenge(verifier), "")
+	authPage := redirectLocation(t, w)
+	require.Equal(t, github.URL+"/login/oauth/authorize", authPage.Scheme+"://"+authPage.Host+authPage.Path)
+	require.Equal(t, testIOSCallback, authPage.Query().Get("redirect_uri"))
+	require.Equal(t, oauth.S256Challenge(verifier), authPage.Query().Get("code_challenge"))
+	require.Equal(t, "S256", authPage.Query().Get("code_challenge_method"))
+	state := authPage.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	// The provider redirects to the callback, which passes the code and the state to the deep link.
+	w = serve(router, http.MethodGet, "/api/v1/oauth2/ios/github/callback?"+url.Values{
+		"code":  {testOauthCode},
+		"state": {state},
+	}.Encode(), "")
+	deepLink := redirectLocation(t, w)
+	require.Equal(t, "renju24", deepLink.Scheme)
+	require.Equal(t, "oauth", deepLink.Host)
+	require.Equal(t, testOauthCode, deepLink.Query().Get("code"))
+	require.Equal(t, state, deepLink.Query().Get("state"))
+	require.Empty(t, deepLink.Query().Get("token"))
+	require.Empty(t, db.sessions)
+
+	tokenRequest := func(verifier string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(&oauthTokenRequest{Code: testOauthCode, State: state, CodeVerifier: verifier})
+		require.NoError(t, err)
+		return serve(router, http.MethodPost, "/api/v1/oauth2/ios/github/token", string(body))
+	}
+
+	// The intercepted deep link is useless without the verifier.
+	otherVerifier, err := oauth.NewVerifier()
+	require.NoError(t, err)
+	w = tokenRequest(otherVerifier)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Empty(t, db.sessions)
+
+	// The app redeems the code with the verifier.
+	w = tokenRequest(verifier)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.NotEmpty(t, resp.Token)
+	require.NotEmpty(t, resp.RefreshToken)
+	require.Equal(t, int(defaultAccessTTL.Seconds()), resp.ExpiresIn)
+	user := db.users["github:583231"]
+	require.NotNil(t, user)
+	require.Equal(t, "octocat", user.Username)
+	require.Equal(t, []int64{user.ID}, db.sessions)
+}
+
+func TestOauth2LoginRequiresChallenge(t *testing.T) {
+	_, router, _ := newOauthTestServer(t, "https://github.example.com")
+	for _, target := range []string{
+		"/api/v1/oauth2/ios/github",
+		"/api/v1/oauth2/ios/github?code_challenge=short",
+	} {
+		deepLink := redirectLocation(t, serve(router, http.MethodGet, target, ""))
+		require.Equal(t, "oauth", deepLink.Host, target)
+		require.NotEmpty(t, deepLink.Query().Get("error"), target)
+	}
+}
+
+func TestOauth2CallbackInvalidState(t *testing.T) {
+	_, router, _ := newOauthTestServer(t, "https://github.example.com")
+	deepLink := redirectLocation(t, serve(router, http.MethodGet, "/api/v1/oauth2/ios/github/callback?code=test-code&state=forged", ""))
+	require.Equal(t, "renju24://oauth", deepLink.String())
+}
+
+func TestOauth2LinkToken(t *testing.T) {
+	api, router, db := newOauthTestServer(t, "https://github.example.com")
+	user, err := db.CreateUserOauth("alice", nil, "42", oauth.Google)
+	require.NoError(t, err)
+	sessionID, err := db.CreateSession(user.ID, "", "", "", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	token, err := api.newAccessToken(user.ID, sessionID)
+	require.NoError(t, err)
+	verifier, err := oauth.NewVerifier()
+	require.NoError(t, err)
+	target := "/api/v1/oauth2/ios/github/link?code_challenge=" + oauth.S256Challenge(verifier)
+
+	// Tokens in URLs leak to logs and Referer, so the query parameter is ignored.
+	deepLink := redirectLocation(t, serve(router, http.MethodGet, target+"&token="+url.QueryEscape(token), ""))
+	require.Equal(t, "renju24://oauth", deepLink.String())
+
+	authPage := redirectLocation(t, serveWithToken(router, http.MethodGet, target, "", token))
+	require.Equal(t, "github.example.com", authPage.Host)
+	state, err := api.decodeOauthState(authPage.Query().Get("state"))
+	require.NoError(t, err)
+	require.Equal(t, user.ID, state.LinkUserID)
+}
+
+func TestOauth2Services(t *testing.T) {
+	_, router, _ := newOauthTestServer(t, "https://github.example.com")
+	testCases := []struct {
+		platform string
+		status   int
+		urls     []string
+	}{
+		{platform: "ios", status: http.StatusOK, urls: []string{"https://renju24.com/api/v1/oauth2/ios/github"}},
+		{platform: "web", status: http.StatusOK, urls: []string{"https://renju24.com/api/v1/oauth2/web/github"}},
+		// Github isn't configured for android.
+		{platform: "android", status: http.StatusOK, urls: []string{}},
+		{platform: "windows", status: http.StatusNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.platform, func(t *testing.T) {
+			w := serve(router, http.MethodGet, "/api/v1/oauth2/"+tc.platform+"/services", "")
+			require.Equal(t, tc.status, w.Code)
+			if tc.status != http.StatusOK {
+				return
+			}
+			var services []struct {
+				Name oauth.Service `json:"name"`
+				URL  string        `json:"url"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &services))
+			urls := []string{}
+			for _, service := range services {
+				require.Equal(t, oauth.Github, service.Name)
+				urls = append(urls, service.URL)
+			}
+			require.Equal(t, tc.urls, urls)
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"github.com/renju24/backend/internal/pkg/ratelimit"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	tokenPurposePasswordReset = "password_reset"
+
+	defaultPasswordResetTTL = time.Hour
+)
+
+var (
+	defaultPasswordResetPerEmail = config.RateLimitConfig{Requests: 3, Period: 3600}
+	defaultPasswordResetPerIP    = config.RateLimitConfig{Requests: 10, Period: 3600}
+)
+
+type passwordResetRequest struct {
+	Email string `json:"email"`
+}
+
+type passwordResetConfirmRequest struct {
+	Token            string `json:"token"`
+	Password         string `json:"password"`
+	RepeatedPassword string `json:"repeated_password"`
+}
+
+type passwordResetResponse struct{}
+
+// newRateLimiter creates limiter from config, the fallback is used if the limit is not configured.
+func newRateLimiter(cfg config.RateLimitConfig, fallback config.RateLimitConfig) *ratelimit.Limiter {
+	if cfg.Requests <= 0 || cfg.Period <= 0 {
+		cfg = fallback
+	}
+	return ratelimit.New(cfg.Requests, time.Duration(cfg.Period)*time.Second)
+}
+
+// requestPasswordReset emails the reset link. The response is the same whether the email is registered or not,
+// so the endpoint can't be used to find out who has an account.
+func requestPasswordReset(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req passwordResetRequest
+		if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+		if err := validateEmail(req.Email); err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: err,
+			})
+			return
+		}
+		if !api.passwordResetIPLimiter.Allow(c.ClientIP()) || !api.passwordResetEmailLimiter.Allow(req.Email) {
+			c.JSON(http.StatusTooManyRequests, &apierror.Error{
+				Error: apierror.ErrorTooManyRequests,
+			})
+			return
+		}
+		user, err := api.db.GetUserByLogin(req.Email)
+		if err != nil {
+			if errors.Is(err, apierror.ErrorUserNotFound) {
+				c.JSON(http.StatusOK, &passwordResetResponse{})
+				return
+			}
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		if err = api.sendPasswordResetEmail(user.ID, user.Username, req.Email); err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, &passwordResetResponse{})
+	}
+}
+
+func (api *APIServer) sendPasswordResetEmail(userID int64, username, email string) error {
+	ttl := time.Duration(api.config.PasswordReset.TokenTTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultPasswordResetTTL
+	}
+	token, hash, err := api.tokenSigner.New(tokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if err = api.db.CreatePasswordResetToken(userID, hash, time.Now().Add(ttl)); err != nil {
+		return err
+	}
+	return api.mailer.Enqueue(email, api.userMailLocale(userID), mailer.TemplatePasswordReset, map[string]any{
+		"Username":  username,
+		"URL":       api.config.Mail.BaseURL + "/password_reset?token=" + url.QueryEscape(token),
+		"ExpiresIn": ttl.String(),
+	})
+}
+
+// confirmPasswordReset sets the new password and signs out all user's devices.
+func confirmPasswordReset(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req passwordResetConfirmRequest
+		if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		if !api.passwordResetIPLimiter.Allow(c.ClientIP()) {
+			c.JSON(http.StatusTooManyRequests, &apierror.Error{
+				Error: apierror.ErrorTooManyRequests,
+			})
+			return
+		}
+		hash, err := api.tokenSigner.Verify(tokenPurposePasswordReset, req.Token)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
+			return
+		}
+		if err := validatePassword(req.Password, req.RepeatedPassword); err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: err,
+			})
+			return
+		}
+		passwordBcrypt, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		userID, err := api.db.ResetPassword(hash, string(passwordBcrypt), time.Now().UTC().Truncate(time.Second))
+		if err != nil {
+			if errors.Is(err, apierror.ErrorInvalidToken) {
+				c.JSON(http.StatusBadRequest, &apierror.Error{
+					Error: apierror.ErrorInvalidToken,
+				})
+				return
+			}
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		// Connected clients authorized with old tokens are disconnected, they can't reconnect.
+		if err = api.centrifugeNode.Disconnect(
+			strconv.FormatInt(userID, 10),
+			centrifuge.WithCustomDisconnect(centrifuge.DisconnectInvalidToken),
+		); err != nil {
+			api.logger.Error().Err(err).Send()
+		}
+		c.JSON(http.StatusOK, &passwordResetResponse{})
+	}
+}
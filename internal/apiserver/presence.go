@@ -0,0 +1,88 @@
+package apiserver
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// isOnline reports whether user has a connection subscribed to his personal channel.
+// The connection with exceptClientID is not taken into account, it's useful when the client is disconnecting.
+func (apiServer *APIServer) isOnline(userID int64, exceptClientID string) (bool, error) {
+	result, err := apiServer.centrifugeNode.Presence(fmt.Sprintf("user_%d", userID))
+	if err != nil {
+		return false, err
+	}
+	for clientID := range result.Presence {
+		if clientID != exceptClientID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// notifyFriends publishes the event into personal channels of all user's friends.
+func (apiServer *APIServer) notifyFriends(userID int64, event Event) {
+	friends, err := apiServer.db.GetFriends(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	for _, friend := range friends {
+		if _, err = apiServer.PublishEvent(fmt.Sprintf("user_%d", friend.ID), event); err != nil {
+			apiServer.logger.Error().Err(err).Send()
+		}
+	}
+}
+
+// onUserChannelSubscribe notifies friends when user comes online with his first connection.
+// It must be called before the subscription is added to the channel presence.
+func (apiServer *APIServer) onUserChannelSubscribe(clientUserID string) {
+	userID, err := strconv.ParseInt(clientUserID, 10, 64)
+	if err != nil {
+		return
+	}
+	online, err := apiServer.isOnline(userID, "")
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	if online {
+		return
+	}
+	go func() {
+		user, err := apiServer.db.GetUserByID(userID)
+		if err != nil {
+			apiServer.logger.Error().Err(err).Send()
+			return
+		}
+		apiServer.notifyFriends(userID, &EventFriendOnline{
+			UserID:   user.ID,
+			Username: user.Username,
+		})
+	}()
+}
+
+// onUserDisconnect notifies friends when user's last connection is closed.
+func (apiServer *APIServer) onUserDisconnect(clientUserID, clientID string) {
+	userID, err := strconv.ParseInt(clientUserID, 10, 64)
+	if err != nil {
+		return
+	}
+	online, err := apiServer.isOnline(userID, clientID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	if online {
+		return
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	apiServer.notifyFriends(userID, &EventFriendOffline{
+		UserID:   user.ID,
+		Username: user.Username,
+	})
+}
@@ -0,0 +1,254 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/avatar"
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/model"
+	"golang.org/x/text/language"
+)
+
+const (
+	avatarsPath           = "/avatars"
+	defaultAvatarDir      = "./avatars"
+	defaultAvatarMaxSize  = 5 << 20
+	maxDisplayNameLength  = 32
+	maxBioLength          = 280
+	multipartFormOverhead = 1 << 20
+)
+
+var defaultAvatarUploads = config.RateLimitConfig{Requests: 10, Period: 3600}
+
+// profileResponse is the public profile, Settings are set only for the owner.
+type profileResponse struct {
+	DisplayName string          `json:"display_name"`
+	Bio         string          `json:"bio"`
+	Country     *string         `json:"country"`
+	Avatar      map[int]string  `json:"avatar,omitempty"`
+	Settings    *model.Settings `json:"settings,omitempty"`
+}
+
+func newProfileResponse(userID int64, profile *model.Profile, owner bool) profileResponse {
+	resp := profileResponse{
+		DisplayName: profile.DisplayName,
+		Bio:         profile.Bio,
+		Country:     profile.Country,
+		Avatar:      avatarURLs(userID, profile.AvatarUpdatedAt),
+	}
+	if owner {
+		resp.Settings = &profile.Settings
+	}
+	return resp
+}
+
+// avatarURLs returns URLs of all avatar sizes, the version parameter busts caches after upload.
+func avatarURLs(userID int64, updatedAt *time.Time) map[int]string {
+	if updatedAt == nil {
+		return nil
+	}
+	version := strconv.FormatInt(updatedAt.Unix(), 10)
+	urls := make(map[int]string, len(avatar.Sizes))
+	for _, size := range avatar.Sizes {
+		urls[size] = avatarsPath + "/" + avatar.Path(userID, size) + "?v=" + version
+	}
+	return urls
+}
+
+func (api *APIServer) avatarMaxSize() int64 {
+	if api.config.Account.AvatarMaxSize > 0 {
+		return api.config.Account.AvatarMaxSize
+	}
+	return defaultAvatarMaxSize
+}
+
+func avatarDir(cfg config.AccountConfig) string {
+	if cfg.AvatarDir != "" {
+		return cfg.AvatarDir
+	}
+	return defaultAvatarDir
+}
+
+// validateDisplayName expects trimmed display name, empty name means the username is shown.
+func validateDisplayName(displayName string) *centrifuge.Error {
+	if utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return apierror.ErrorInvalidDisplayName
+	}
+	for _, char := range displayName {
+		if !unicode.IsGraphic(char) || (unicode.IsSpace(char) && char != ' ') {
+			return apierror.ErrorInvalidDisplayName
+		}
+	}
+	return nil
+}
+
+// normalizeBio removes control characters except line breaks.
+func normalizeBio(bio string) (string, *centrifuge.Error) {
+	bio = strings.TrimSpace(strings.Map(func(char rune) rune {
+		if char == '\n' || unicode.IsGraphic(char) {
+			return char
+		}
+		return -1
+	}, bio))
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return "", apierror.ErrorBioIsTooLong
+	}
+	return bio, nil
+}
+
+// normalizeCountry returns ISO 3166-1 alpha-2 code, empty code removes the country.
+func normalizeCountry(country string) (*string, *centrifuge.Error) {
+	if country == "" {
+		return nil, nil
+	}
+	region, err := language.ParseRegion(country)
+	if err != nil || len(country) != 2 || !region.IsCountry() {
+		return nil, apierror.ErrorInvalidCountry
+	}
+	code := region.String()
+	return &code, nil
+}
+
+// normalizeLanguage returns BCP 47 tag in canonical form, e.g. "en-US".
+func normalizeLanguage(lang string) (*string, *centrifuge.Error) {
+	if lang == "" {
+		return nil, nil
+	}
+	tag, err := language.Parse(lang)
+	if err != nil || len(lang) > 16 {
+		return nil, apierror.ErrorInvalidLanguage
+	}
+	canonical := tag.String()
+	return &canonical, nil
+}
+
+func validateBoardTheme(theme string) *centrifuge.Error {
+	for _, boardTheme := range model.BoardThemes {
+		if theme == boardTheme {
+			return nil
+		}
+	}
+	return apierror.ErrorInvalidBoardTheme
+}
+
+// requestToken returns the access token from the header or the cookie.
+func (api *APIServer) requestToken(c *gin.Context) string {
+	if header := c.GetHeader(api.config.Server.Token.Header.Name); header != "" {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer"))
+	}
+	token, _ := c.Cookie(api.config.Server.Token.Cookie.Name)
+	return token
+}
+
+type avatarResponse struct {
+	Avatar map[int]string `json:"avatar"`
+}
+
+// uploadAvatar accepts multipart form with "avatar" file, the image is re-encoded to PNG of avatar.Sizes.
+func uploadAvatar(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := api.authorizedUserID(api.requestToken(c))
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, &apierror.Error{
+				Error: apierror.ErrorUnauthorized,
+			})
+			return
+		}
+		if ok, retryAfter := api.avatarLimiter.Reserve(strconv.FormatInt(userID, 10)); !ok {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
+		maxSize := api.avatarMaxSize()
+		if c.Request.ContentLength > maxSize+multipartFormOverhead {
+			c.JSON(http.StatusRequestEntityTooLarge, &apierror.Error{
+				Error: apierror.ErrorImageTooLarge,
+			})
+			return
+		}
+		// Body without Content-Length is cut as well.
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartFormOverhead)
+		fileHeader, err := c.FormFile("avatar")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		if fileHeader.Size > maxSize {
+			c.JSON(http.StatusRequestEntityTooLarge, &apierror.Error{
+				Error: apierror.ErrorImageTooLarge,
+			})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		defer file.Close()
+		if err = api.avatars.Save(userID, file); err != nil {
+			switch {
+			case errors.Is(err, avatar.ErrInvalidImage):
+				c.JSON(http.StatusBadRequest, &apierror.Error{
+					Error: apierror.ErrorInvalidImage,
+				})
+			case errors.Is(err, avatar.ErrImageTooLarge):
+				c.JSON(http.StatusBadRequest, &apierror.Error{
+					Error: apierror.ErrorImageTooLarge,
+				})
+			default:
+				api.logger.Error().Err(err).Send()
+				c.JSON(http.StatusInternalServerError, &apierror.Error{
+					Error: apierror.ErrorInternal,
+				})
+			}
+			return
+		}
+		now := time.Now()
+		if err = api.db.SetAvatarUpdatedAt(userID, &now); err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, &avatarResponse{
+			Avatar: avatarURLs(userID, &now),
+		})
+	}
+}
+
+func deleteAvatar(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := api.authorizedUserID(api.requestToken(c))
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, &apierror.Error{
+				Error: apierror.ErrorUnauthorized,
+			})
+			return
+		}
+		if err := api.db.SetAvatarUpdatedAt(userID, nil); err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		if err := api.avatars.Delete(userID); err != nil {
+			api.logger.Error().Err(err).Send()
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
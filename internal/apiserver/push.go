@@ -0,0 +1,70 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/renju24/backend/internal/pkg/webpush"
+)
+
+// sendPush delivers the event to all user's browsers if user is offline.
+// Online users receive events through the websocket connection.
+func (apiServer *APIServer) sendPush(userID int64, event Event) {
+	// Bots have no devices to notify.
+	if apiServer.pushSender == nil || apiServer.isBot(userID) {
+		return
+	}
+	online, err := apiServer.isOnline(userID, "")
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	if online {
+		return
+	}
+	payload, err := json.Marshal(map[string]any{
+		"event_type": event.EventType(),
+		"data":       event,
+	})
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	subs, err := apiServer.db.GetPushSubscriptions(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return
+	}
+	for _, sub := range subs {
+		// Subscriptions saved before the endpoint rules were tightened are removed too.
+		if err = sub.Validate(apiServer.runMode != "prod"); err == nil {
+			err = apiServer.pushSender.Send(sub, payload)
+		}
+		if errors.Is(err, webpush.ErrSubscriptionGone) || errors.Is(err, webpush.ErrInvalidSubscription) {
+			if err = apiServer.db.DeletePushSubscription(userID, sub.Endpoint); err != nil {
+				apiServer.logger.Error().Err(err).Send()
+			}
+			continue
+		}
+		if err != nil {
+			apiServer.logger.Warn().Err(err).Int64("user_id", userID).Msg("could not send push notification")
+		}
+	}
+}
+
+func webPushPublicKey(api *APIServer) gin.HandlerFunc {
+	type response struct {
+		PublicKey string `json:"public_key"`
+	}
+	return func(c *gin.Context) {
+		if api.pushSender == nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		c.JSON(http.StatusOK, &response{
+			PublicKey: api.pushSender.PublicKey(),
+		})
+	}
+}
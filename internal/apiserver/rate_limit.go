@@ -0,0 +1,105 @@
+package apiserver
+
+import (
+	"strings"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/internal/pkg/ratelimit"
+)
+
+var (
+	defaultAuthPerIP      = config.RateLimitConfig{Requests: 20, Period: 60}
+	defaultSignInPerLogin = config.RateLimitConfig{Requests: 10, Period: 900}
+	defaultRPCPerUser     = config.RateLimitConfig{Requests: 100, Period: 10}
+	defaultLockout        = config.LockoutConfig{Failures: 5, Duration: 30, MaxDuration: 900}
+	defaultRPCViolations  = config.RateLimitConfig{Requests: 10, Period: 60}
+
+	// defaultRPCMethodQuotas limit the methods which query database heavily or notify other users.
+	defaultRPCMethodQuotas = map[string]config.RateLimitConfig{
+		"find_users":          {Requests: 10, Period: 10},
+		"call_for_game":       {Requests: 5, Period: 60},
+		"send_message":        {Requests: 20, Period: 10},
+		"send_friend_request": {Requests: 10, Period: 60},
+		"report_message":      {Requests: 10, Period: 60},
+		"game_history":        {Requests: 20, Period: 10},
+		"chat_history":        {Requests: 20, Period: 10},
+		"top_10":              {Requests: 20, Period: 10},
+	}
+
+	// disconnectFlood closes the connection of the client which keeps exceeding RPC limits, it may reconnect later.
+	disconnectFlood = centrifuge.Disconnect{
+		Code:      4429,
+		Reason:    "too many requests",
+		Reconnect: true,
+	}
+)
+
+// rpcLimiter applies the per-user limit of all RPC calls and the per-user quotas of single methods.
+type rpcLimiter struct {
+	total   *ratelimit.Limiter
+	methods map[string]*ratelimit.Limiter
+	// violations is keyed by client ID, every rejected call takes a token.
+	violations *ratelimit.Limiter
+}
+
+func newRPCLimiter(cfg config.RateLimitsConfig) *rpcLimiter {
+	l := &rpcLimiter{
+		total:      newRateLimiter(cfg.RPC, defaultRPCPerUser),
+		methods:    make(map[string]*ratelimit.Limiter),
+		violations: newRateLimiter(cfg.RPCViolations, defaultRPCViolations),
+	}
+	for method, quota := range defaultRPCMethodQuotas {
+		l.methods[method] = newRateLimiter(cfg.RPCMethods[method], quota)
+	}
+	for method, quota := range cfg.RPCMethods {
+		if _, ok := l.methods[method]; !ok && quota.Requests > 0 && quota.Period > 0 {
+			l.methods[method] = newRateLimiter(quota, quota)
+		}
+	}
+	return l
+}
+
+// Reserve returns how long the user has to wait if the call is over the limit.
+func (l *rpcLimiter) Reserve(userID, method string) (ok bool, retryAfter time.Duration) {
+	if ok, retryAfter = l.total.Reserve(userID); !ok {
+		return false, retryAfter
+	}
+	if limiter, exists := l.methods[method]; exists {
+		return limiter.Reserve(userID)
+	}
+	return true, 0
+}
+
+// Violation records the rejected call and reports whether the client must be disconnected.
+func (l *rpcLimiter) Violation(clientID string) (disconnect bool) {
+	return !l.violations.Allow(clientID)
+}
+
+func newLockout(cfg config.LockoutConfig) *ratelimit.Lockout {
+	if cfg.Failures <= 0 || cfg.Duration <= 0 {
+		cfg = defaultLockout
+	}
+	return ratelimit.NewLockout(cfg.Failures, time.Duration(cfg.Duration)*time.Second, time.Duration(cfg.MaxDuration)*time.Second)
+}
+
+func clientIPKey(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// signInLoginKey reads login from the request body, the body is cached for the handler.
+func signInLoginKey(c *gin.Context) string {
+	var req signinRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		return ""
+	}
+	return loginKey(req.Login)
+}
+
+// loginKey is the same for all spellings of the login.
+func loginKey(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRPCLimiterReserve(t *testing.T) {
+	type call struct {
+		userID     string
+		method     string
+		ok         bool
+		retryAfter time.Duration
+	}
+	testCases := []struct {
+		name  string
+		cfg   config.RateLimitsConfig
+		calls []call
+	}{
+		{
+			name: "total limit",
+			cfg:  config.RateLimitsConfig{RPC: config.RateLimitConfig{Requests: 2, Period: 10}},
+			calls: []call{
+				{userID: "1", method: "get_user", ok: true},
+				{userID: "1", method: "make_move", ok: true},
+				{userID: "1", method: "get_user", retryAfter: 5 * time.Second},
+				// Users have separate buckets.
+				{userID: "2", method: "get_user", ok: true},
+			},
+		},
+		{
+			name: "default method quota",
+			cfg:  config.RateLimitsConfig{RPC: config.RateLimitConfig{Requests: 100, Period: 10}},
+			calls: []call{
+				{userID: "1", method: "call_for_game", ok: true},
+				{userID: "1", method: "call_for_game", ok: true},
+				{userID: "1", method: "call_for_game", ok: true},
+				{userID: "1", method: "call_for_game", ok: true},
+				{userID: "1", method: "call_for_game", ok: true},
+				{userID: "1", method: "call_for_game", retryAfter: 12 * time.Second},
+				// The other methods are limited only by the total limit.
+				{userID: "1", method: "get_user", ok: true},
+			},
+		},
+		{
+			name: "configured method quota",
+			cfg: config.RateLimitsConfig{
+				RPC:        config.RateLimitConfig{Requests: 100, Period: 10},
+				RPCMethods: map[string]config.RateLimitConfig{"get_user": {Requests: 1, Period: 60}},
+			},
+			calls: []call{
+				{userID: "1", method: "get_user", ok: true},
+				{userID: "1", method: "get_user", retryAfter: time.Minute},
+			},
+		},
+		{
+			name: "defaults",
+			cfg:  config.RateLimitsConfig{},
+			calls: []call{
+				{userID: "1", method: "get_user", ok: true},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newRPCLimiter(tc.cfg)
+			for i, c := range tc.calls {
+				ok, retryAfter := l.Reserve(c.userID, c.method)
+				require.Equal(t, c.ok, ok, "call %d", i)
+				require.InDelta(t, c.retryAfter.Seconds(), retryAfter.Seconds(), 0.1, "call %d", i)
+			}
+		})
+	}
+}
+
+func TestRPCLimiterViolation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		violations config.RateLimitConfig
+		disconnect []bool
+	}{
+		{
+			name:       "configured",
+			violations: config.RateLimitConfig{Requests: 2, Period: 60},
+			disconnect: []bool{false, false, true, true},
+		},
+		{
+			name:       "default",
+			disconnect: []bool{false, false, false, false, false, false, false, false, false, false, true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l := newRPCLimiter(config.RateLimitsConfig{RPCViolations: tc.violations})
+			for i, expected := range tc.disconnect {
+				require.Equal(t, expected, l.Violation("client"), "violation %d", i)
+			}
+			// Violations are counted per connection.
+			require.False(t, l.Violation("other client"))
+		})
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	require.Equal(t, 1, retryAfterSeconds(time.Millisecond))
+	require.Equal(t, 1, retryAfterSeconds(time.Second))
+	require.Equal(t, 2, retryAfterSeconds(1500*time.Millisecond))
+}
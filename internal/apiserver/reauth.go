@@ -0,0 +1,47 @@
+package apiserver
+
+import (
+	"errors"
+	"time"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+// reauthWindow is how long after signing in users without password can change credentials.
+const reauthWindow = 10 * time.Minute
+
+// confirmPasswordless checks the user who has no password to confirm the change with:
+// the second factor if it's enabled, otherwise the session must be created recently by signing in with OAuth2.
+func (apiServer *APIServer) confirmPasswordless(c *websocket.Client, user *model.User, code string) error {
+	if user.TwoFactorEnabled {
+		err := apiServer.verifySecondFactor(user.ID, code)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, apierror.ErrorTooManyRequests):
+			return apierror.ErrorTooManyRequests
+		case errors.Is(err, apierror.ErrorInvalidTwoFactorCode):
+			return apierror.ErrorInvalidTwoFactorCode
+		case errors.Is(err, apierror.ErrorTwoFactorNotEnabled):
+			return apierror.ErrorTwoFactorNotEnabled
+		case errors.Is(err, apierror.ErrorUserNotFound):
+			return apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return apierror.ErrorInternal
+	}
+	session, err := apiServer.db.GetSession(clientSessionID(c))
+	if err != nil {
+		if errors.Is(err, apierror.ErrorSessionNotFound) {
+			return apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return apierror.ErrorInternal
+	}
+	if time.Since(session.CreatedAt) > reauthWindow {
+		return apierror.ErrorReauthenticationRequired
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCAcceptFriendRequestRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCAcceptFriendRequestResponse struct{}
+
+func (apiServer *APIServer) AcceptFriendRequest(c *websocket.Client, jsonData []byte) (*RPCAcceptFriendRequestResponse, error) {
+	var req RPCAcceptFriendRequestRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, requester, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == requester.ID {
+		return nil, apierror.ErrorFriendRequestToYourself
+	}
+	if err = apiServer.db.AcceptFriendRequest(requester.ID, user.ID); err != nil {
+		if errors.Is(err, apierror.ErrorFriendRequestNotFound) {
+			return nil, apierror.ErrorFriendRequestNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if _, err = apiServer.Notify(requester.ID, &EventFriendRequestAccepted{
+		UserID:   user.ID,
+		Username: user.Username,
+	}); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	return &RPCAcceptFriendRequestResponse{}, nil
+}
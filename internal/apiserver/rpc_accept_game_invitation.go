@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -33,6 +34,27 @@ func (apiServer *APIServer) AcceptGameInvitation(c *websocket.Client, jsonData [
 	if !isGameMember {
 		return nil, apierror.ErrorPermissionDenied
 	}
+	// The invitation could be sent before one of the users blocked the other.
+	game, err := apiServer.db.GetGameByID(req.GameID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorGameNotFound) {
+			return nil, apierror.ErrorGameNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	inviterID := game.BlackUserID
+	if inviterID == opponentID {
+		inviterID = game.WhiteUserID
+	}
+	blocked, err := apiServer.db.IsBlocked(opponentID, inviterID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if blocked {
+		return nil, apierror.ErrorUserIsBlocked
+	}
 	// Change game status and started_at in database.
 	if err = apiServer.db.StartGame(req.GameID); err != nil {
 		apiServer.logger.Error().Err(err).Send()
@@ -0,0 +1,33 @@
+package apiserver
+
+import (
+	"encoding/json"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCBlockUserRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCBlockUserResponse struct{}
+
+func (apiServer *APIServer) BlockUser(c *websocket.Client, jsonData []byte) (*RPCBlockUserResponse, error) {
+	var req RPCBlockUserRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, blocked, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == blocked.ID {
+		return nil, apierror.ErrorBlockingYourself
+	}
+	if err = apiServer.db.BlockUser(user.ID, blocked.ID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCBlockUserResponse{}, nil
+}
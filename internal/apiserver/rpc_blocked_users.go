@@ -0,0 +1,29 @@
+package apiserver
+
+import (
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+func (apiServer *APIServer) BlockedUsers(c *websocket.Client, _ []byte) (*RPCFindUserResponse, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	users, err := apiServer.db.GetBlockedUsers(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	var response RPCFindUserResponse
+	for _, user := range users {
+		response.Users = append(response.Users, findUser{
+			ID:       user.ID,
+			Username: user.Username,
+			Ranking:  user.Ranking,
+		})
+	}
+	return &response, nil
+}
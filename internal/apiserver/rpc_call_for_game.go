@@ -16,10 +16,14 @@ import (
 
 type RPCCallForGameRequest struct {
 	Username string `json:"username"`
+	// Difficulty is the bot level from 1 to 5, it's used only if the opponent is the bot.
+	Difficulty int `json:"difficulty"`
 }
 
 type RPCCallForGameResponse struct {
 	GameID int64 `json:"game_id"`
+	// Started is true for the game against the bot, it doesn't wait for acceptance.
+	Started bool `json:"started"`
 }
 
 func (apiServer *APIServer) CallForGame(c *websocket.Client, jsonData []byte) (*RPCCallForGameResponse, error) {
@@ -46,6 +50,9 @@ func (apiServer *APIServer) CallForGame(c *websocket.Client, jsonData []byte) (*
 	if inviter.Username == req.Username {
 		return nil, apierror.ErrorCallingYourselfForGame
 	}
+	if apiServer.config.EmailVerification.RestrictInvitations && !inviter.EmailVerified {
+		return nil, apierror.ErrorEmailNotVerified
+	}
 	// If inviter is already playing a game.
 	ok, err := apiServer.db.IsPlaying(inviterID)
 	if err != nil {
@@ -63,6 +70,18 @@ func (apiServer *APIServer) CallForGame(c *websocket.Client, jsonData []byte) (*
 		apiServer.logger.Error().Err(err).Send()
 		return nil, apierror.ErrorInternal
 	}
+	if apiServer.isBot(opponent.ID) {
+		return apiServer.callBotForGame(inviterID, req.Difficulty)
+	}
+	// If one of the users has blocked the other one.
+	ok, err = apiServer.db.IsBlocked(inviterID, opponent.ID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if ok {
+		return nil, apierror.ErrorUserIsBlocked
+	}
 	// If opponent is already playing a game.
 	ok, err = apiServer.db.IsPlaying(opponent.ID)
 	if err != nil {
@@ -72,22 +91,25 @@ func (apiServer *APIServer) CallForGame(c *websocket.Client, jsonData []byte) (*
 	if ok {
 		return nil, apierror.ErrorOpponentAlreadyPlaying
 	}
+	// Games with unverified users may be configured to not change rankings.
+	rated := !apiServer.config.EmailVerification.RestrictRatedGames || (inviter.EmailVerified && opponent.EmailVerified)
 	// Creating game in database with random black and white user and retrieve the game id.
-	gameID, err := apiServer.db.CreateGame(randomBlackAndWhite(inviterID, opponent.ID))
+	blackUserID, whiteUserID := randomBlackAndWhite(inviterID, opponent.ID)
+	gameID, err := apiServer.db.CreateGame(blackUserID, whiteUserID, rated)
 	if err != nil {
 		apiServer.logger.Error().Err(err).Send()
 		return nil, apierror.ErrorInternal
 	}
-	opponentChannel := fmt.Sprintf("user_%d", opponent.ID)
-	_, err = apiServer.PublishEvent(opponentChannel, &EventGameInvitation{
+	invitation := &EventGameInvitation{
 		GameID:    gameID,
 		Inviter:   inviter.Username,
 		InvitedAt: time.Now(),
-	})
-	if err != nil {
+	}
+	if _, err = apiServer.Notify(opponent.ID, invitation); err != nil {
 		apiServer.logger.Error().Err(err).Send()
 		return nil, apierror.ErrorInternal
 	}
+	go apiServer.sendPush(opponent.ID, invitation)
 	// Waiting opponent for 60 second and close the game.
 	go func(opponentID, gameID int64) {
 		time.Sleep(60 * time.Second)
@@ -107,12 +129,32 @@ func (apiServer *APIServer) CallForGame(c *websocket.Client, jsonData []byte) (*
 			apiServer.logger.Warn().Err(err).Send()
 		}
 	}(opponent.ID, gameID)
-	// TODO: send push notifications.
 	return &RPCCallForGameResponse{
 		GameID: gameID,
 	}, nil
 }
 
+// callBotForGame starts the unrated game against the bot, the bot plays any number of games at once.
+func (apiServer *APIServer) callBotForGame(userID int64, difficulty int) (*RPCCallForGameResponse, error) {
+	level, ok := apiServer.botLevel(difficulty)
+	if !ok {
+		return nil, apierror.ErrorInvalidBotLevel
+	}
+	blackUserID, whiteUserID := randomBlackAndWhite(userID, apiServer.botUser.ID)
+	gameID, err := apiServer.db.CreateBotGame(blackUserID, whiteUserID, int(level))
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if apiServer.isBot(blackUserID) {
+		go apiServer.playBotMove(gameID)
+	}
+	return &RPCCallForGameResponse{
+		GameID:  gameID,
+		Started: true,
+	}, nil
+}
+
 var randUser = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 func randomBlackAndWhite(user1, user2 int64) (blackUserID, whiteUserID int64) {
@@ -0,0 +1,38 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCCancelFriendRequestRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCCancelFriendRequestResponse struct{}
+
+// CancelFriendRequest withdraws the pending request which the user has sent.
+func (apiServer *APIServer) CancelFriendRequest(c *websocket.Client, jsonData []byte) (*RPCCancelFriendRequestResponse, error) {
+	var req RPCCancelFriendRequestRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, addressee, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == addressee.ID {
+		return nil, apierror.ErrorFriendRequestToYourself
+	}
+	if err = apiServer.db.DeleteFriendRequest(user.ID, addressee.ID); err != nil {
+		if errors.Is(err, apierror.ErrorFriendRequestNotFound) {
+			return nil, apierror.ErrorFriendRequestNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCCancelFriendRequestResponse{}, nil
+}
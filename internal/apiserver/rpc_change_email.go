@@ -0,0 +1,79 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RPCChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	// Code is TOTP code or recovery code, users without password confirm the change with it if two-factor authentication is enabled.
+	Code string `json:"code"`
+}
+
+type RPCChangeEmailResponse struct{}
+
+// ChangeEmail sets the new email and sends verification link to it.
+// Users with password have to confirm the change with it, users without password with the second factor
+// or by signing in again. The old address is notified about the change.
+func (apiServer *APIServer) ChangeEmail(c *websocket.Client, jsonData []byte) (*RPCChangeEmailResponse, error) {
+	var req RPCChangeEmailRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Email = strings.ToLower(strings.TrimSpace(req.Email))
+	if err := validateEmail(req.Email); err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.PasswordBcrypt != nil {
+		if bcrypt.CompareHashAndPassword([]byte(*user.PasswordBcrypt), []byte(req.Password)) != nil {
+			return nil, apierror.ErrorInvalidCredentials
+		}
+	} else if err = apiServer.confirmPasswordless(c, user, req.Code); err != nil {
+		return nil, err
+	}
+	if user.Email != nil && *user.Email == req.Email {
+		return &RPCChangeEmailResponse{}, nil
+	}
+	if err = apiServer.db.UpdateEmail(userID, req.Email); err != nil {
+		if errors.Is(err, apierror.ErrorEmailIsTaken) {
+			return nil, apierror.ErrorEmailIsTaken
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.Email != nil {
+		err = apiServer.mailer.Enqueue(*user.Email, apiServer.userMailLocale(userID), mailer.TemplateEmailChanged, map[string]any{
+			"Username": user.Username,
+			"Email":    req.Email,
+		})
+		if err != nil {
+			apiServer.logger.Error().Err(err).Send()
+		}
+	}
+	user.Email = &req.Email
+	if err = apiServer.sendVerificationEmail(user); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	return &RPCChangeEmailResponse{}, nil
+}
@@ -0,0 +1,75 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RPCChangePasswordRequest struct {
+	CurrentPassword  string `json:"current_password"`
+	Password         string `json:"password"`
+	RepeatedPassword string `json:"repeated_password"`
+	// Code is TOTP code or recovery code, users without password confirm the change with it if two-factor authentication is enabled.
+	Code string `json:"code"`
+}
+
+type RPCChangePasswordResponse struct {
+	// Token replaces the access token of the current session, the old ones are revoked with the password.
+	Token string `json:"token"`
+}
+
+// ChangePassword requires the current password. Users signed up with OAuth2 set the first one with the second factor
+// or shortly after signing in.
+// All other sessions are signed out and their clients are disconnected.
+func (apiServer *APIServer) ChangePassword(c *websocket.Client, jsonData []byte) (*RPCChangePasswordResponse, error) {
+	var req RPCChangePasswordRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.PasswordBcrypt != nil {
+		if bcrypt.CompareHashAndPassword([]byte(*user.PasswordBcrypt), []byte(req.CurrentPassword)) != nil {
+			return nil, apierror.ErrorInvalidCredentials
+		}
+	} else if err = apiServer.confirmPasswordless(c, user, req.Code); err != nil {
+		return nil, err
+	}
+	if err := validatePassword(req.Password, req.RepeatedPassword); err != nil {
+		return nil, err
+	}
+	passwordBcrypt, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	sessionID := clientSessionID(c)
+	sessionIDs, err := apiServer.db.UpdatePassword(userID, string(passwordBcrypt), sessionID, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	go apiServer.disconnectSessions(userID, sessionIDs...)
+	token, err := apiServer.newAccessToken(userID, sessionID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCChangePasswordResponse{Token: token}, nil
+}
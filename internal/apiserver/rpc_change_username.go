@@ -0,0 +1,72 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+const (
+	defaultUsernameChangeInterval = 30 * 24 * time.Hour
+	defaultUsernameReservation    = 90 * 24 * time.Hour
+)
+
+type RPCChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCChangeUsernameResponse struct {
+	Username string `json:"username"`
+}
+
+func (apiServer *APIServer) ChangeUsername(c *websocket.Client, jsonData []byte) (*RPCChangeUsernameResponse, error) {
+	var req RPCChangeUsernameRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Username = strings.ToLower(strings.TrimSpace(req.Username))
+	if err := validateUsername(req.Username); err != nil {
+		return nil, err
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.Username == req.Username {
+		return &RPCChangeUsernameResponse{Username: user.Username}, nil
+	}
+	changeInterval := time.Duration(apiServer.config.Account.UsernameChangeInterval) * 24 * time.Hour
+	if changeInterval <= 0 {
+		changeInterval = defaultUsernameChangeInterval
+	}
+	reservation := time.Duration(apiServer.config.Account.UsernameReservation) * 24 * time.Hour
+	if reservation <= 0 {
+		reservation = defaultUsernameReservation
+	}
+	if err = apiServer.db.ChangeUsername(userID, req.Username, changeInterval, reservation); err != nil {
+		switch {
+		case errors.Is(err, apierror.ErrorUsernameIsTaken):
+			return nil, apierror.ErrorUsernameIsTaken
+		case errors.Is(err, apierror.ErrorUsernameChangeTooSoon):
+			return nil, apierror.ErrorUsernameChangeTooSoon
+		case errors.Is(err, apierror.ErrorUserNotFound):
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCChangeUsernameResponse{Username: req.Username}, nil
+}
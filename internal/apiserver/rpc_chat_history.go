@@ -0,0 +1,45 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+type RPCChatHistoryRequest struct {
+	GameID int64 `json:"game_id"`
+}
+
+type RPCChatHistoryResponse struct {
+	Messages []model.Message `json:"messages"`
+}
+
+func (app *APIServer) ChatHistory(c *websocket.Client, jsonData []byte) (*RPCChatHistoryResponse, error) {
+	var req RPCChatHistoryRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	isGameMember, err := app.db.IsGameMember(userID, req.GameID)
+	if err != nil {
+		app.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if !isGameMember {
+		return nil, apierror.ErrorPermissionDenied
+	}
+	messages, err := app.db.GetGameMessages(req.GameID)
+	if err != nil {
+		app.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCChatHistoryResponse{
+		Messages: messages,
+	}, nil
+}
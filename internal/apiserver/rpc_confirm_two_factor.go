@@ -0,0 +1,68 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/totp"
+)
+
+type RPCConfirmTwoFactorRequest struct {
+	Code string `json:"code"`
+}
+
+type RPCConfirmTwoFactorResponse struct {
+	// RecoveryCodes are shown once, each of them can be used instead of TOTP code one time.
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (apiServer *APIServer) ConfirmTwoFactor(c *websocket.Client, jsonData []byte) (*RPCConfirmTwoFactorResponse, error) {
+	var req RPCConfirmTwoFactorRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if !apiServer.twoFactorLimiter.Allow(c.UserID()) {
+		return nil, apierror.ErrorTooManyRequests
+	}
+	secret, enabled, err := apiServer.db.GetTotpSecret(userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apierror.ErrorTwoFactorNotEnabled):
+			return nil, apierror.ErrorTwoFactorNotEnabled
+		case errors.Is(err, apierror.ErrorUserNotFound):
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if enabled {
+		return nil, apierror.ErrorTwoFactorAlreadyEnabled
+	}
+	counter, ok := totp.Verify(secret, req.Code, time.Now())
+	if !ok {
+		return nil, apierror.ErrorInvalidTwoFactorCode
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.db.EnableTotp(userID, counter, hashes); err != nil {
+		if errors.Is(err, apierror.ErrorTwoFactorAlreadyEnabled) {
+			return nil, apierror.ErrorTwoFactorAlreadyEnabled
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCConfirmTwoFactorResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
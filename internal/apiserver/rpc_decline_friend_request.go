@@ -0,0 +1,37 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCDeclineFriendRequestRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCDeclineFriendRequestResponse struct{}
+
+func (apiServer *APIServer) DeclineFriendRequest(c *websocket.Client, jsonData []byte) (*RPCDeclineFriendRequestResponse, error) {
+	var req RPCDeclineFriendRequestRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, requester, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == requester.ID {
+		return nil, apierror.ErrorFriendRequestToYourself
+	}
+	if err = apiServer.db.DeleteFriendRequest(requester.ID, user.ID); err != nil {
+		if errors.Is(err, apierror.ErrorFriendRequestNotFound) {
+			return nil, apierror.ErrorFriendRequestNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCDeclineFriendRequestResponse{}, nil
+}
@@ -0,0 +1,95 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/centrifugal/centrifuge"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RPCDeleteAccountRequest struct {
+	// Username confirms the deletion.
+	Username string `json:"username"`
+	// Password is required if user has one.
+	Password string `json:"password"`
+	// Code is TOTP code or recovery code, it's required if two-factor authentication is enabled.
+	Code string `json:"code"`
+}
+
+type RPCDeleteAccountResponse struct{}
+
+// DeleteAccount anonymises the user, opponents' games stay intact. The export endpoint should be used before.
+func (apiServer *APIServer) DeleteAccount(c *websocket.Client, jsonData []byte) (*RPCDeleteAccountResponse, error) {
+	var req RPCDeleteAccountRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if !strings.EqualFold(strings.TrimSpace(req.Username), user.Username) {
+		return nil, apierror.ErrorDeletionConfirmation
+	}
+	if user.PasswordBcrypt != nil {
+		if bcrypt.CompareHashAndPassword([]byte(*user.PasswordBcrypt), []byte(req.Password)) != nil {
+			return nil, apierror.ErrorInvalidCredentials
+		}
+	}
+	if user.TwoFactorEnabled {
+		if err = apiServer.verifySecondFactor(userID, req.Code); err != nil {
+			switch {
+			case errors.Is(err, apierror.ErrorTooManyRequests):
+				return nil, apierror.ErrorTooManyRequests
+			case errors.Is(err, apierror.ErrorInvalidTwoFactorCode):
+				return nil, apierror.ErrorInvalidTwoFactorCode
+			case errors.Is(err, apierror.ErrorTwoFactorNotEnabled):
+				return nil, apierror.ErrorTwoFactorNotEnabled
+			}
+			apiServer.logger.Error().Err(err).Send()
+			return nil, apierror.ErrorInternal
+		}
+	}
+	// The running game would be left without the player.
+	isPlaying, err := apiServer.db.IsPlaying(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if isPlaying {
+		return nil, apierror.ErrorAccountDeletionDuringGame
+	}
+	if err = apiServer.db.DeleteAccount(userID); err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.avatars.Delete(userID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	// The current client receives the reply, its session is deleted, so it's disconnected on the next refresh.
+	if err = apiServer.centrifugeNode.Disconnect(
+		c.UserID(),
+		centrifuge.WithDisconnectClientWhitelist([]string{c.ID()}),
+		centrifuge.WithCustomDisconnect(centrifuge.DisconnectInvalidToken),
+	); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	apiServer.logger.Info().Int64("user_id", userID).Msg("account deleted")
+	return &RPCDeleteAccountResponse{}, nil
+}
@@ -0,0 +1,48 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCDeleteMessageRequest struct {
+	MessageID int64 `json:"message_id"`
+}
+
+type RPCDeleteMessageResponse struct{}
+
+func (apiServer *APIServer) DeleteMessage(c *websocket.Client, jsonData []byte) (*RPCDeleteMessageResponse, error) {
+	var req RPCDeleteMessageRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	if _, err := apiServer.requireAdmin(c); err != nil {
+		return nil, err
+	}
+	message, err := apiServer.db.GetMessageByID(req.MessageID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorMessageNotFound) {
+			return nil, apierror.ErrorMessageNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.db.DeleteMessage(message.ID); err != nil {
+		if errors.Is(err, apierror.ErrorMessageNotFound) {
+			return nil, apierror.ErrorMessageNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	// Let game members remove the message from their chat.
+	if _, err = apiServer.PublishEvent(fmt.Sprintf("game_%d", message.GameID), &EventChatMessageDeleted{
+		MessageID: message.ID,
+	}); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	return &RPCDeleteMessageResponse{}, nil
+}
@@ -0,0 +1,47 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCDisableTwoFactorRequest struct {
+	// Code is TOTP code or recovery code.
+	Code string `json:"code"`
+}
+
+type RPCDisableTwoFactorResponse struct{}
+
+func (apiServer *APIServer) DisableTwoFactor(c *websocket.Client, jsonData []byte) (*RPCDisableTwoFactorResponse, error) {
+	var req RPCDisableTwoFactorRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.verifySecondFactor(userID, req.Code); err != nil {
+		switch {
+		case errors.Is(err, apierror.ErrorTooManyRequests):
+			return nil, apierror.ErrorTooManyRequests
+		case errors.Is(err, apierror.ErrorTwoFactorNotEnabled):
+			return nil, apierror.ErrorTwoFactorNotEnabled
+		case errors.Is(err, apierror.ErrorInvalidTwoFactorCode):
+			return nil, apierror.ErrorInvalidTwoFactorCode
+		case errors.Is(err, apierror.ErrorUserNotFound):
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.db.DisableTotp(userID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCDisableTwoFactorResponse{}, nil
+}
@@ -0,0 +1,67 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type RPCEnableTwoFactorRequest struct {
+	Password string `json:"password"`
+}
+
+type RPCEnableTwoFactorResponse struct {
+	Secret string `json:"secret"`
+	// URI is shown as QR code to add the account to an authenticator app.
+	URI string `json:"otpauth_uri"`
+}
+
+// EnableTwoFactor starts enrolment, two-factor authentication is enabled after confirmation with a code.
+// The password is required, so a stolen session can't lock the owner out.
+func (apiServer *APIServer) EnableTwoFactor(c *websocket.Client, jsonData []byte) (*RPCEnableTwoFactorResponse, error) {
+	var req RPCEnableTwoFactorRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.TwoFactorEnabled {
+		return nil, apierror.ErrorTwoFactorAlreadyEnabled
+	}
+	if user.PasswordBcrypt != nil {
+		if bcrypt.CompareHashAndPassword([]byte(*user.PasswordBcrypt), []byte(req.Password)) != nil {
+			return nil, apierror.ErrorInvalidCredentials
+		}
+	}
+	secret, err := totp.NewSecret()
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.db.SetTotpSecret(userID, secret); err != nil {
+		if errors.Is(err, apierror.ErrorTwoFactorAlreadyEnabled) {
+			return nil, apierror.ErrorTwoFactorAlreadyEnabled
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCEnableTwoFactorResponse{
+		Secret: secret,
+		URI:    totp.URI(apiServer.twoFactorIssuer(), user.Username, secret),
+	}, nil
+}
@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/armantarkhanian/websocket"
@@ -22,7 +23,7 @@ type findUser struct {
 	Ranking  int    `json:"ranking"`
 }
 
-func (app *APIServer) FindUsers(_ *websocket.Client, jsonData []byte) (*RPCFindUserResponse, error) {
+func (app *APIServer) FindUsers(c *websocket.Client, jsonData []byte) (*RPCFindUserResponse, error) {
 	var req RPCFindUserRequest
 	if err := json.Unmarshal(jsonData, &req); err != nil {
 		return nil, apierror.ErrorBadRequest
@@ -31,7 +32,11 @@ func (app *APIServer) FindUsers(_ *websocket.Client, jsonData []byte) (*RPCFindU
 	if req.Username == "" {
 		return nil, apierror.ErrorUsernameIsRequired
 	}
-	users, err := app.db.FindUsers(req.Username)
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	users, err := app.db.FindUsers(req.Username, userID)
 	if err != nil {
 		app.logger.Error().Err(err).Send()
 		return nil, apierror.ErrorInternal
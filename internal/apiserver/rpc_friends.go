@@ -0,0 +1,71 @@
+package apiserver
+
+import (
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+type RPCFriendsResponse struct {
+	Friends  []friend              `json:"friends"`
+	Requests []model.FriendRequest `json:"requests"`
+}
+
+type friend struct {
+	ID       int64       `json:"id"`
+	Username string      `json:"username"`
+	Ranking  int         `json:"ranking"`
+	Online   bool        `json:"online"`
+	Game     *friendGame `json:"game"`
+}
+
+type friendGame struct {
+	GameID   int64  `json:"game_id"`
+	Opponent string `json:"opponent"`
+}
+
+func (apiServer *APIServer) Friends(c *websocket.Client, _ []byte) (*RPCFriendsResponse, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	friends, err := apiServer.db.GetFriends(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	var response RPCFriendsResponse
+	for _, user := range friends {
+		f := friend{
+			ID:       user.ID,
+			Username: user.Username,
+			Ranking:  user.Ranking,
+		}
+		if f.Online, err = apiServer.isOnline(user.ID, ""); err != nil {
+			apiServer.logger.Error().Err(err).Send()
+			return nil, apierror.ErrorInternal
+		}
+		game, err := apiServer.db.GetPlayingGame(user.ID)
+		if err != nil {
+			apiServer.logger.Error().Err(err).Send()
+			return nil, apierror.ErrorInternal
+		}
+		if game != nil {
+			f.Game = &friendGame{
+				GameID:   game.ID,
+				Opponent: game.BlackUsername,
+			}
+			if game.BlackUsername == user.Username {
+				f.Game.Opponent = game.WhiteUsername
+			}
+		}
+		response.Friends = append(response.Friends, f)
+	}
+	if response.Requests, err = apiServer.db.GetFriendRequests(userID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &response, nil
+}
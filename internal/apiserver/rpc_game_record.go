@@ -0,0 +1,35 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCGameRecordRequest struct {
+	GameID int64 `json:"game_id"`
+}
+
+type RPCGameRecordResponse struct {
+	Record string `json:"record"`
+}
+
+func (app *APIServer) GameRecord(_ *websocket.Client, jsonData []byte) (*RPCGameRecordResponse, error) {
+	var req RPCGameRecordRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	game, err := app.db.GetFinishedGameRecord(req.GameID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorGameNotFound) {
+			return nil, apierror.ErrorGameNotFound
+		}
+		app.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCGameRecordResponse{
+		Record: newGameRecord(game).String(),
+	}, nil
+}
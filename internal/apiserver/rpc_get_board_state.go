@@ -13,7 +13,8 @@ type RPCBoardStateRequest struct {
 }
 
 type RPCBoardStateResponse struct {
-	Moves []EventMove `json:"moves"`
+	Moves           []EventMove      `json:"moves"`
+	ForbiddenPoints []ForbiddenPoint `json:"forbidden_points"`
 }
 
 func (app *APIServer) BoardState(c *websocket.Client, jsonData []byte) (*RPCBoardStateResponse, error) {
@@ -33,6 +34,11 @@ func (app *APIServer) BoardState(c *websocket.Client, jsonData []byte) (*RPCBoar
 	if !isGameMember {
 		return nil, apierror.ErrorPermissionDenied
 	}
+	game, err := app.db.GetGameByID(req.GameID)
+	if err != nil {
+		app.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
 	moves, err := app.db.GetGameMovesByID(req.GameID)
 	if err != nil {
 		app.logger.Error().Err(err).Send()
@@ -45,6 +51,11 @@ func (app *APIServer) BoardState(c *websocket.Client, jsonData []byte) (*RPCBoar
 			XCoordinate: move.XCoordinate,
 			YCoordinate: move.YCoordinate,
 		})
+		if _, err = game.ApplyMove(move.UserID, move.XCoordinate, move.YCoordinate); err != nil {
+			app.logger.Error().Err(err).Int64("game_id", req.GameID).Send()
+			return nil, apierror.ErrorInternal
+		}
 	}
+	response.ForbiddenPoints = newForbiddenPoints(game.ForbiddenPoints())
 	return &response, nil
 }
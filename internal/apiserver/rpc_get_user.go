@@ -15,10 +15,12 @@ type RPCGetUserRequest struct {
 }
 
 type RPCGetUserResponse struct {
-	ID       int64   `json:"id"`
-	Username string  `json:"username"`
-	Email    *string `json:"email,omitempty"`
-	Ranking  int     `json:"ranking"`
+	ID            int64   `json:"id"`
+	Username      string  `json:"username"`
+	Email         *string `json:"email,omitempty"`
+	EmailVerified *bool   `json:"email_verified,omitempty"`
+	Ranking       int     `json:"ranking"`
+	profileResponse
 }
 
 func (apiServer *APIServer) GetUser(c *websocket.Client, jsonData []byte) (*RPCGetUserResponse, error) {
@@ -38,14 +40,27 @@ func (apiServer *APIServer) GetUser(c *websocket.Client, jsonData []byte) (*RPCG
 		apiServer.logger.Error().Err(err).Send()
 		return nil, apierror.ErrorInternal
 	}
+	profile, err := apiServer.db.GetProfile(user.ID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUserNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	owner := strconv.FormatInt(user.ID, 10) == c.UserID()
 	resp := RPCGetUserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		Ranking:  user.Ranking,
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerified:   &user.EmailVerified,
+		Ranking:         user.Ranking,
+		profileResponse: newProfileResponse(user.ID, profile, owner),
 	}
-	if strconv.FormatInt(user.ID, 10) != c.UserID() {
+	// Email and settings are private.
+	if !owner {
 		resp.Email = nil
+		resp.EmailVerified = nil
 	}
 	return &resp, nil
 }
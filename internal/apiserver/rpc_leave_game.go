@@ -55,6 +55,7 @@ func (app *APIServer) LeaveGame(c *websocket.Client, jsonData []byte) (*RPCLeave
 			app.logger.Error().Err(err).Send()
 		}
 		event.WinnerID = &winnerID
+		go app.sendPush(winnerID, event)
 	} else {
 		//  Otherwise, just delete a game.
 		if err = app.db.DeleteGame(req.GameID); err != nil {
@@ -0,0 +1,39 @@
+package apiserver
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+)
+
+type RPCLinkedAccountsResponse struct {
+	Services    []oauth.Service `json:"services"`
+	HasPassword bool            `json:"has_password"`
+}
+
+func (apiServer *APIServer) LinkedAccounts(c *websocket.Client, _ []byte) (*RPCLinkedAccountsResponse, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	services, err := apiServer.db.GetOauthServices(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCLinkedAccountsResponse{
+		Services:    services,
+		HasPassword: user.PasswordBcrypt != nil,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+const (
+	defaultNotificationsLimit = 20
+	maxNotificationsLimit     = 100
+)
+
+type RPCListNotificationsRequest struct {
+	UnreadOnly bool  `json:"unread_only"`
+	BeforeID   int64 `json:"before_id"`
+	Limit      int   `json:"limit"`
+}
+
+type RPCListNotificationsResponse struct {
+	Notifications []model.Notification `json:"notifications"`
+	UnreadCount   int                  `json:"unread_count"`
+}
+
+func (apiServer *APIServer) ListNotifications(c *websocket.Client, jsonData []byte) (*RPCListNotificationsResponse, error) {
+	var req RPCListNotificationsRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultNotificationsLimit
+	}
+	if req.Limit > maxNotificationsLimit {
+		req.Limit = maxNotificationsLimit
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	notifications, err := apiServer.db.ListNotifications(userID, req.UnreadOnly, req.BeforeID, req.Limit)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	unreadCount, err := apiServer.db.CountUnreadNotifications(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCListNotificationsResponse{
+		Notifications: notifications,
+		UnreadCount:   unreadCount,
+	}, nil
+}
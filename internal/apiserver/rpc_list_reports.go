@@ -0,0 +1,35 @@
+package apiserver
+
+import (
+	"encoding/json"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+type RPCListReportsRequest struct {
+	OnlyOpen bool `json:"only_open"`
+}
+
+type RPCListReportsResponse struct {
+	Reports []model.Report `json:"reports"`
+}
+
+func (apiServer *APIServer) ListReports(c *websocket.Client, jsonData []byte) (*RPCListReportsResponse, error) {
+	var req RPCListReportsRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	if _, err := apiServer.requireAdmin(c); err != nil {
+		return nil, err
+	}
+	reports, err := apiServer.db.ListReports(req.OnlyOpen)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCListReportsResponse{
+		Reports: reports,
+	}, nil
+}
@@ -29,75 +29,100 @@ func (apiServer *APIServer) MakeMove(c *websocket.Client, jsonData []byte) (*RPC
 	if err != nil {
 		return nil, apierror.ErrorUnauthorized
 	}
+	if err = apiServer.makeMove(userID, &req); err != nil {
+		return nil, err
+	}
+	return &RPCMakeMoveResponse{}, nil
+}
+
+// makeMove applies the move of the user or the bot, publishes it and finishes the game if there is a winner.
+func (apiServer *APIServer) makeMove(userID int64, req *RPCMakeMoveRequest) error {
 	// Check game in database.
 	game, err := apiServer.db.GetGameByID(req.GameID)
 	if err != nil {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorInternal
+		return apierror.ErrorInternal
 	}
 	if game.Status != model.InProgress {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorGameIsNotActive
+		return apierror.ErrorGameIsNotActive
 	}
 	// Check if user is a game member.
 	isGameMember, err := apiServer.db.IsGameMember(userID, req.GameID)
 	if err != nil {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorInternal
+		return apierror.ErrorInternal
 	}
 	if !isGameMember {
-		return nil, apierror.ErrorPermissionDenied
+		return apierror.ErrorPermissionDenied
 	}
 	// Retrieve previous game moves from database and apply them all.
 	moves, err := apiServer.db.GetGameMovesByID(req.GameID)
 	if err != nil {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorInternal
+		return apierror.ErrorInternal
 	}
 	// Clear board before applying all moves.
 	game.ClearBoard()
 	for _, move := range moves {
 		if _, err = game.ApplyMove(move.UserID, move.XCoordinate, move.YCoordinate); err != nil {
-			return nil, err
+			return err
 		}
 	}
 	// Apply next move.
-	winnerColor, err := game.ApplyMove(userID, req.XCoordinate, req.YCoordinate)
+	result, err := game.Play(userID, req.XCoordinate, req.YCoordinate)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	winnerColor := result.Winner
 	// If success then add move into database.
 	if err = apiServer.db.CreateMove(req.GameID, userID, req.XCoordinate, req.YCoordinate); err != nil {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorInternal
+		return apierror.ErrorInternal
 	}
 	// Publish move into game's channel.
 	gameChannel := fmt.Sprintf("game_%d", game.ID)
 	// Publish move.
-	if _, err = apiServer.PublishEvent(gameChannel, &EventMove{
-		UserID:      userID,
-		XCoordinate: req.XCoordinate,
-		YCoordinate: req.YCoordinate,
-	}); err != nil {
+	moveEvent := &EventMove{
+		UserID:          userID,
+		XCoordinate:     req.XCoordinate,
+		YCoordinate:     req.YCoordinate,
+		ForbiddenPoints: newForbiddenPoints(game.ForbiddenPoints()),
+		Fours:           newThreats(result.Fours),
+		OpenThrees:      newThreats(result.OpenThrees),
+		Forced:          result.Forced,
+	}
+	if _, err = apiServer.PublishEvent(gameChannel, moveEvent); err != nil {
 		apiServer.logger.Error().Err(err).Send()
-		return nil, apierror.ErrorInternal
+		return apierror.ErrorInternal
+	}
+	opponentID := game.BlackUserID
+	if opponentID == userID {
+		opponentID = game.WhiteUserID
 	}
 	// Finish game if there is a winner.
 	if winnerColor != pkggame.Nil {
 		winnerID := game.GetUserIDByColor(winnerColor)
 		if err = apiServer.db.FinishGameWithWinner(req.GameID, winnerID); err != nil {
 			apiServer.logger.Error().Err(err).Send()
-			return nil, apierror.ErrorInternal
+			return apierror.ErrorInternal
 		}
 		// Publish event.
-		if _, err = apiServer.PublishEvent(gameChannel, &EventGameEndedWithWinner{
-			WinnerID: winnerID,
-		}); err != nil {
+		gameEndedEvent := &EventGameEndedWithWinner{
+			WinnerID:    winnerID,
+			WinningLine: newPoints(result.WinningLine),
+		}
+		if _, err = apiServer.PublishEvent(gameChannel, gameEndedEvent); err != nil {
 			apiServer.logger.Error().Err(err).Send()
-			return nil, apierror.ErrorInternal
+			return apierror.ErrorInternal
 		}
+		go apiServer.sendPush(opponentID, gameEndedEvent)
 	}
-	return &RPCMakeMoveResponse{}, nil
+	// The bot answers the user's move.
+	if winnerColor == pkggame.Nil && game.BotLevel != nil && apiServer.isBot(opponentID) {
+		go apiServer.playBotMove(game.ID)
+	}
+	return nil
 }
 
 var (
@@ -0,0 +1,41 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCMarkNotificationsReadRequest struct {
+	// Empty NotificationIDs means all notifications.
+	NotificationIDs []int64 `json:"notification_ids"`
+}
+
+type RPCMarkNotificationsReadResponse struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+func (apiServer *APIServer) MarkNotificationsRead(c *websocket.Client, jsonData []byte) (*RPCMarkNotificationsReadResponse, error) {
+	var req RPCMarkNotificationsReadRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.db.MarkNotificationsRead(userID, req.NotificationIDs); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	unreadCount, err := apiServer.db.CountUnreadNotifications(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCMarkNotificationsReadResponse{
+		UnreadCount: unreadCount,
+	}, nil
+}
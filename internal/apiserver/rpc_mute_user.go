@@ -0,0 +1,69 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+const maxMuteDuration = 365 * 24 * time.Hour
+
+type RPCMuteUserRequest struct {
+	Username        string `json:"username"`
+	DurationSeconds int64  `json:"duration_seconds"`
+	Reason          string `json:"reason"`
+}
+
+type RPCMuteUserResponse struct {
+	MutedUntil time.Time `json:"muted_until"`
+}
+
+func (apiServer *APIServer) MuteUser(c *websocket.Client, jsonData []byte) (*RPCMuteUserResponse, error) {
+	var req RPCMuteUserRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		return nil, apierror.ErrorUsernameIsRequired
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > 256 {
+		return nil, apierror.ErrorBadRequest
+	}
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration <= 0 || duration > maxMuteDuration {
+		return nil, apierror.ErrorInvalidMuteDuration
+	}
+	admin, err := apiServer.requireAdmin(c)
+	if err != nil {
+		return nil, err
+	}
+	user, err := apiServer.db.GetUserByLogin(req.Username)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUserNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	mutedUntil := time.Now().Add(duration).Truncate(time.Second)
+	if err = apiServer.db.MuteUser(user.ID, admin.ID, req.Reason, mutedUntil); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if _, err = apiServer.Notify(user.ID, &EventUserMuted{
+		MutedUntil: mutedUntil,
+		Reason:     req.Reason,
+	}); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	return &RPCMuteUserResponse{
+		MutedUntil: mutedUntil,
+	}, nil
+}
@@ -0,0 +1,35 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/webpush"
+)
+
+type RPCRegisterPushSubscriptionRequest struct {
+	Subscription webpush.Subscription `json:"subscription"`
+}
+
+type RPCRegisterPushSubscriptionResponse struct{}
+
+func (apiServer *APIServer) RegisterPushSubscription(c *websocket.Client, jsonData []byte) (*RPCRegisterPushSubscriptionResponse, error) {
+	var req RPCRegisterPushSubscriptionRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	if err := req.Subscription.Validate(apiServer.runMode != "prod"); err != nil {
+		return nil, apierror.ErrorInvalidPushSubscription
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.db.SavePushSubscription(userID, &req.Subscription); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCRegisterPushSubscriptionResponse{}, nil
+}
@@ -0,0 +1,37 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCRemoveFriendRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCRemoveFriendResponse struct{}
+
+func (apiServer *APIServer) RemoveFriend(c *websocket.Client, jsonData []byte) (*RPCRemoveFriendResponse, error) {
+	var req RPCRemoveFriendRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, friend, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == friend.ID {
+		return nil, apierror.ErrorFriendRequestToYourself
+	}
+	if err = apiServer.db.RemoveFriend(user.ID, friend.ID); err != nil {
+		if errors.Is(err, apierror.ErrorNotFriends) {
+			return nil, apierror.ErrorNotFriends
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCRemoveFriendResponse{}, nil
+}
@@ -0,0 +1,61 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCReportMessageRequest struct {
+	MessageID int64  `json:"message_id"`
+	Reason    string `json:"reason"`
+}
+
+type RPCReportMessageResponse struct {
+	ReportID int64 `json:"report_id"`
+}
+
+func (apiServer *APIServer) ReportMessage(c *websocket.Client, jsonData []byte) (*RPCReportMessageResponse, error) {
+	var req RPCReportMessageRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if utf8.RuneCountInString(req.Reason) > 256 {
+		return nil, apierror.ErrorBadRequest
+	}
+	reporterID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	message, err := apiServer.db.GetMessageByID(req.MessageID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorMessageNotFound) {
+			return nil, apierror.ErrorMessageNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	// Only game members can see the chat, so only they can report it.
+	isGameMember, err := apiServer.db.IsGameMember(reporterID, message.GameID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if !isGameMember {
+		return nil, apierror.ErrorPermissionDenied
+	}
+	reportID, err := apiServer.db.CreateReport(message.ID, reporterID, req.Reason)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCReportMessageResponse{
+		ReportID: reportID,
+	}, nil
+}
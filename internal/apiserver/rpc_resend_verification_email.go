@@ -0,0 +1,52 @@
+package apiserver
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+const defaultEmailVerificationResendInterval = time.Minute
+
+type RPCResendVerificationEmailResponse struct{}
+
+func (apiServer *APIServer) ResendVerificationEmail(c *websocket.Client, _ []byte) (*RPCResendVerificationEmailResponse, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	user, err := apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if user.EmailVerified {
+		return nil, apierror.ErrorEmailAlreadyVerified
+	}
+	if user.Email == nil {
+		return nil, apierror.ErrorEmailIsRequired
+	}
+	interval := time.Duration(apiServer.config.EmailVerification.ResendInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultEmailVerificationResendInterval
+	}
+	sentAt, err := apiServer.db.LastEmailVerificationSentAt(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if time.Since(sentAt) < interval {
+		return nil, apierror.ErrorTooManyRequests
+	}
+	if err = apiServer.sendVerificationEmail(user); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCResendVerificationEmailResponse{}, nil
+}
@@ -0,0 +1,34 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCResolveReportRequest struct {
+	ReportID int64 `json:"report_id"`
+}
+
+type RPCResolveReportResponse struct{}
+
+func (apiServer *APIServer) ResolveReport(c *websocket.Client, jsonData []byte) (*RPCResolveReportResponse, error) {
+	var req RPCResolveReportRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	admin, err := apiServer.requireAdmin(c)
+	if err != nil {
+		return nil, err
+	}
+	if err = apiServer.db.ResolveReport(req.ReportID, admin.ID); err != nil {
+		if errors.Is(err, apierror.ErrorReportNotFound) {
+			return nil, apierror.ErrorReportNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCResolveReportResponse{}, nil
+}
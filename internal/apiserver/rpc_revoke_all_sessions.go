@@ -0,0 +1,42 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCRevokeAllSessionsRequest struct {
+	// KeepCurrent leaves the session of the client making the request signed in.
+	KeepCurrent bool `json:"keep_current"`
+}
+
+type RPCRevokeAllSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+func (apiServer *APIServer) RevokeAllSessions(c *websocket.Client, jsonData []byte) (*RPCRevokeAllSessionsResponse, error) {
+	var req RPCRevokeAllSessionsRequest
+	if len(jsonData) > 0 {
+		if err := json.Unmarshal(jsonData, &req); err != nil {
+			return nil, apierror.ErrorBadRequest
+		}
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	var keepSessionID int64
+	if req.KeepCurrent {
+		keepSessionID = clientSessionID(c)
+	}
+	sessionIDs, err := apiServer.db.RevokeSessions(userID, keepSessionID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	go apiServer.disconnectSessions(userID, sessionIDs...)
+	return &RPCRevokeAllSessionsResponse{Revoked: len(sessionIDs)}, nil
+}
@@ -0,0 +1,38 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCRevokeSessionRequest struct {
+	SessionID int64 `json:"session_id"`
+}
+
+type RPCRevokeSessionResponse struct{}
+
+// RevokeSession signs out the device, its refresh token stops working and its websocket clients are disconnected.
+func (apiServer *APIServer) RevokeSession(c *websocket.Client, jsonData []byte) (*RPCRevokeSessionResponse, error) {
+	var req RPCRevokeSessionRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.db.RevokeSession(userID, req.SessionID); err != nil {
+		if errors.Is(err, apierror.ErrorSessionNotFound) {
+			return nil, apierror.ErrorSessionNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	// Disconnecting the current client must not block its RPC reply.
+	go apiServer.disconnectSessions(userID, req.SessionID)
+	return &RPCRevokeSessionResponse{}, nil
+}
@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+type RPCSendFriendRequestRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCSendFriendRequestResponse struct {
+	// Accepted is true when the other user has already sent a friend request, so users became friends.
+	Accepted bool `json:"accepted"`
+}
+
+func (apiServer *APIServer) SendFriendRequest(c *websocket.Client, jsonData []byte) (*RPCSendFriendRequestResponse, error) {
+	var req RPCSendFriendRequestRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, other, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == other.ID {
+		return nil, apierror.ErrorFriendRequestToYourself
+	}
+	blocked, err := apiServer.db.IsBlocked(user.ID, other.ID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if blocked {
+		return nil, apierror.ErrorUserIsBlocked
+	}
+	status, err := apiServer.db.GetFriendshipStatus(user.ID, other.ID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	switch status {
+	case model.Friends:
+		return nil, apierror.ErrorAlreadyFriends
+	case model.RequestSent:
+		return nil, apierror.ErrorFriendRequestAlreadySent
+	case model.RequestReceived:
+		// Both users want to be friends, so just accept the existing request.
+		if err = apiServer.db.AcceptFriendRequest(other.ID, user.ID); err != nil {
+			apiServer.logger.Error().Err(err).Send()
+			return nil, apierror.ErrorInternal
+		}
+		if _, err = apiServer.Notify(other.ID, &EventFriendRequestAccepted{
+			UserID:   user.ID,
+			Username: user.Username,
+		}); err != nil {
+			apiServer.logger.Error().Err(err).Send()
+		}
+		return &RPCSendFriendRequestResponse{Accepted: true}, nil
+	}
+	if err = apiServer.db.CreateFriendRequest(user.ID, other.ID); err != nil {
+		if errors.Is(err, apierror.ErrorFriendRequestAlreadySent) {
+			return nil, apierror.ErrorFriendRequestAlreadySent
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if _, err = apiServer.Notify(other.ID, &EventFriendRequest{
+		UserID:   user.ID,
+		Username: user.Username,
+	}); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+	}
+	return &RPCSendFriendRequestResponse{}, nil
+}
+
+// currentAndTargetUser returns the connected user and the user with the given username.
+// Both may be the same user, callers reject it with their own error.
+func (apiServer *APIServer) currentAndTargetUser(c *websocket.Client, username string) (user, other *model.User, err error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, nil, apierror.ErrorUsernameIsRequired
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, nil, apierror.ErrorUnauthorized
+	}
+	user, err = apiServer.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, nil, apierror.ErrorInternal
+	}
+	other, err = apiServer.db.GetUserByLogin(username)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, nil, apierror.ErrorUserNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, nil, apierror.ErrorInternal
+	}
+	return user, other, nil
+}
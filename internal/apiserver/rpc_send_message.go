@@ -0,0 +1,105 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/moderation"
+)
+
+const defaultMaxMessageLength = 300
+
+type RPCSendMessageRequest struct {
+	GameID int64  `json:"game_id"`
+	Text   string `json:"text"`
+}
+
+type RPCSendMessageResponse struct {
+	MessageID int64 `json:"message_id"`
+}
+
+func (apiServer *APIServer) SendMessage(c *websocket.Client, jsonData []byte) (*RPCSendMessageResponse, error) {
+	var req RPCSendMessageRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		return nil, apierror.ErrorMessageIsEmpty
+	}
+	maxLength := apiServer.config.Chat.MaxMessageLength
+	if maxLength <= 0 {
+		maxLength = defaultMaxMessageLength
+	}
+	if utf8.RuneCountInString(req.Text) > maxLength {
+		return nil, apierror.ErrorMessageIsTooLong
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	isGameMember, err := apiServer.db.IsGameMember(userID, req.GameID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if !isGameMember {
+		return nil, apierror.ErrorPermissionDenied
+	}
+	mute, err := apiServer.db.GetActiveMute(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if mute != nil {
+		return nil, apierror.ErrorUserIsMuted
+	}
+	game, err := apiServer.db.GetGameByID(req.GameID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	opponentID := game.BlackUserID
+	if opponentID == userID {
+		opponentID = game.WhiteUserID
+	}
+	blocked, err := apiServer.db.IsBlocked(userID, opponentID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if blocked {
+		return nil, apierror.ErrorUserIsBlocked
+	}
+	if err = apiServer.messageFilter.Check(req.Text); err != nil {
+		if errors.Is(err, moderation.ErrForbiddenWord) {
+			return nil, apierror.ErrorMessageRejected
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	message, err := apiServer.db.CreateMessage(req.GameID, userID, req.Text)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if _, err = apiServer.PublishEvent(fmt.Sprintf("game_%d", req.GameID), &EventChatMessage{
+		MessageID: message.ID,
+		UserID:    message.UserID,
+		Username:  message.Username,
+		Text:      message.Text,
+		SentAt:    message.SentAt,
+	}); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCSendMessageResponse{
+		MessageID: message.ID,
+	}, nil
+}
@@ -0,0 +1,42 @@
+package apiserver
+
+import (
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+type RPCSessionsResponse struct {
+	Sessions []RPCSession `json:"sessions"`
+}
+
+type RPCSession struct {
+	*model.Session
+	// Current is the session of the client making the request.
+	Current bool `json:"current"`
+}
+
+func (apiServer *APIServer) Sessions(c *websocket.Client, _ []byte) (*RPCSessionsResponse, error) {
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	sessions, err := apiServer.db.GetActiveSessions(userID)
+	if err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	currentSessionID := clientSessionID(c)
+	resp := RPCSessionsResponse{
+		Sessions: make([]RPCSession, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, RPCSession{
+			Session: session,
+			Current: session.ID == currentSessionID,
+		})
+	}
+	return &resp, nil
+}
@@ -0,0 +1,33 @@
+package apiserver
+
+import (
+	"encoding/json"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCUnblockUserRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCUnblockUserResponse struct{}
+
+func (apiServer *APIServer) UnblockUser(c *websocket.Client, jsonData []byte) (*RPCUnblockUserResponse, error) {
+	var req RPCUnblockUserRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	user, blocked, err := apiServer.currentAndTargetUser(c, req.Username)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == blocked.ID {
+		return nil, apierror.ErrorBlockingYourself
+	}
+	if err = apiServer.db.UnblockUser(user.ID, blocked.ID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCUnblockUserResponse{}, nil
+}
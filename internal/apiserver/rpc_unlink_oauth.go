@@ -0,0 +1,46 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	oauth "github.com/renju24/backend/internal/pkg/oauth2"
+)
+
+type RPCUnlinkOauthRequest struct {
+	Service string `json:"service"`
+}
+
+type RPCUnlinkOauthResponse struct{}
+
+func (apiServer *APIServer) UnlinkOauth(c *websocket.Client, jsonData []byte) (*RPCUnlinkOauthResponse, error) {
+	var req RPCUnlinkOauthRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	// Accounts of the removed providers can still be unlinked.
+	service := oauth.Service(req.Service)
+	if service == "" {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.db.UnlinkOauth(userID, service); err != nil {
+		switch {
+		case errors.Is(err, apierror.ErrorOauthServiceNotLinked):
+			return nil, apierror.ErrorOauthServiceNotLinked
+		case errors.Is(err, apierror.ErrorLastLoginMethod):
+			return nil, apierror.ErrorLastLoginMethod
+		case errors.Is(err, apierror.ErrorUserNotFound):
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCUnlinkOauthResponse{}, nil
+}
@@ -0,0 +1,43 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCUnmuteUserRequest struct {
+	Username string `json:"username"`
+}
+
+type RPCUnmuteUserResponse struct{}
+
+func (apiServer *APIServer) UnmuteUser(c *websocket.Client, jsonData []byte) (*RPCUnmuteUserResponse, error) {
+	var req RPCUnmuteUserRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	req.Username = strings.TrimSpace(req.Username)
+	if req.Username == "" {
+		return nil, apierror.ErrorUsernameIsRequired
+	}
+	if _, err := apiServer.requireAdmin(c); err != nil {
+		return nil, err
+	}
+	user, err := apiServer.db.GetUserByLogin(req.Username)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUserNotFound
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if err = apiServer.db.UnmuteUser(user.ID); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCUnmuteUserResponse{}, nil
+}
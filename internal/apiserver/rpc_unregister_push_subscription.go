@@ -0,0 +1,34 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+type RPCUnregisterPushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+}
+
+type RPCUnregisterPushSubscriptionResponse struct{}
+
+func (apiServer *APIServer) UnregisterPushSubscription(c *websocket.Client, jsonData []byte) (*RPCUnregisterPushSubscriptionResponse, error) {
+	var req RPCUnregisterPushSubscriptionRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	if req.Endpoint == "" {
+		return nil, apierror.ErrorInvalidPushSubscription
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	if err = apiServer.db.DeletePushSubscription(userID, req.Endpoint); err != nil {
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCUnregisterPushSubscriptionResponse{}, nil
+}
@@ -0,0 +1,95 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/armantarkhanian/websocket"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+// RPCUpdateProfileRequest changes only the fields which are set, empty strings remove optional fields.
+type RPCUpdateProfileRequest struct {
+	DisplayName      *string `json:"display_name"`
+	Bio              *string `json:"bio"`
+	Country          *string `json:"country"`
+	Language         *string `json:"language"`
+	BoardTheme       *string `json:"board_theme"`
+	BoardCoordinates *bool   `json:"board_coordinates"`
+	MoveNumbers      *bool   `json:"move_numbers"`
+}
+
+type RPCUpdateProfileResponse struct {
+	profileResponse
+}
+
+func (apiServer *APIServer) UpdateProfile(c *websocket.Client, jsonData []byte) (*RPCUpdateProfileResponse, error) {
+	var req RPCUpdateProfileRequest
+	if err := json.Unmarshal(jsonData, &req); err != nil {
+		return nil, apierror.ErrorBadRequest
+	}
+	userID, err := strconv.ParseInt(c.UserID(), 10, 64)
+	if err != nil {
+		return nil, apierror.ErrorUnauthorized
+	}
+	profile, err := apiServer.db.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	if req.DisplayName != nil {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		if err := validateDisplayName(displayName); err != nil {
+			return nil, err
+		}
+		profile.DisplayName = displayName
+	}
+	if req.Bio != nil {
+		bio, err := normalizeBio(*req.Bio)
+		if err != nil {
+			return nil, err
+		}
+		profile.Bio = bio
+	}
+	if req.Country != nil {
+		country, err := normalizeCountry(strings.TrimSpace(*req.Country))
+		if err != nil {
+			return nil, err
+		}
+		profile.Country = country
+	}
+	if req.Language != nil {
+		lang, err := normalizeLanguage(strings.TrimSpace(*req.Language))
+		if err != nil {
+			return nil, err
+		}
+		profile.Settings.Language = lang
+	}
+	if req.BoardTheme != nil {
+		if err := validateBoardTheme(*req.BoardTheme); err != nil {
+			return nil, err
+		}
+		profile.Settings.BoardTheme = *req.BoardTheme
+	}
+	if req.BoardCoordinates != nil {
+		profile.Settings.BoardCoordinates = *req.BoardCoordinates
+	}
+	if req.MoveNumbers != nil {
+		profile.Settings.MoveNumbers = *req.MoveNumbers
+	}
+	if err = apiServer.db.UpdateProfile(userID, profile); err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorUnauthorized
+		}
+		apiServer.logger.Error().Err(err).Send()
+		return nil, apierror.ErrorInternal
+	}
+	return &RPCUpdateProfileResponse{
+		profileResponse: newProfileResponse(userID, profile, true),
+	}, nil
+}
@@ -0,0 +1,314 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/armantarkhanian/jwt"
+	"github.com/armantarkhanian/websocket"
+	"github.com/centrifugal/centrifuge"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+const (
+	tokenPurposeRefresh = "refresh"
+	refreshCookiePath   = "/api/v1/refresh"
+
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// authTokens are issued on sign in. The access token is a short-lived JWT of the session,
+// the refresh token is a random one-time token that prolongs the session and returns a new pair.
+type authTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn is the access token lifetime in seconds.
+	ExpiresIn int `json:"expires_in"`
+}
+
+// authorization is the result of access token verification.
+type authorization struct {
+	User      *model.User
+	Session   *model.Session
+	ExpiresAt time.Time
+}
+
+// sessionClients maps sessions to websocket clients, so clients of the revoked session can be disconnected.
+type sessionClients struct {
+	mu      sync.Mutex
+	clients map[int64]map[string]struct{}
+}
+
+func newSessionClients() *sessionClients {
+	return &sessionClients{
+		clients: make(map[int64]map[string]struct{}),
+	}
+}
+
+func (s *sessionClients) Add(sessionID int64, clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.clients[sessionID] == nil {
+		s.clients[sessionID] = make(map[string]struct{})
+	}
+	s.clients[sessionID][clientID] = struct{}{}
+}
+
+func (s *sessionClients) Remove(sessionID int64, clientID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.clients[sessionID], clientID)
+	if len(s.clients[sessionID]) == 0 {
+		delete(s.clients, sessionID)
+	}
+}
+
+func (s *sessionClients) Clients(sessionID int64) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clients := make([]string, 0, len(s.clients[sessionID]))
+	for clientID := range s.clients[sessionID] {
+		clients = append(clients, clientID)
+	}
+	return clients
+}
+
+func (api *APIServer) accessTTL() time.Duration {
+	if api.config.Server.Token.AccessTTL > 0 {
+		return time.Duration(api.config.Server.Token.AccessTTL) * time.Second
+	}
+	return defaultAccessTTL
+}
+
+func (api *APIServer) refreshTTL() time.Duration {
+	if api.config.Server.Token.RefreshTTL > 0 {
+		return time.Duration(api.config.Server.Token.RefreshTTL) * time.Second
+	}
+	if api.config.Server.Token.Cookie.MaxAge > 0 {
+		return time.Duration(api.config.Server.Token.Cookie.MaxAge) * time.Second
+	}
+	return defaultRefreshTTL
+}
+
+func (api *APIServer) refreshCookieName() string {
+	if api.config.Server.Token.RefreshCookie != "" {
+		return api.config.Server.Token.RefreshCookie
+	}
+	return api.config.Server.Token.Cookie.Name + "_refresh"
+}
+
+func (api *APIServer) newAccessToken(userID, sessionID int64) (string, error) {
+	now := time.Now()
+	return api.jwt.Encode(jwt.Payload{
+		Subject:        strconv.FormatInt(userID, 10),
+		JWTID:          strconv.FormatInt(sessionID, 10),
+		IssuedAt:       now.Unix(),
+		ExpirationTime: now.Add(api.accessTTL()).Unix(),
+	})
+}
+
+// newSession signs in user on the device which made the request.
+func (api *APIServer) newSession(c *gin.Context, userID int64) (*authTokens, error) {
+	refreshToken, hash, err := api.tokenSigner.New(tokenPurposeRefresh)
+	if err != nil {
+		return nil, err
+	}
+	sessionID, err := api.db.CreateSession(userID, hash, c.Request.UserAgent(), c.ClientIP(), time.Now().Add(api.refreshTTL()))
+	if err != nil {
+		return nil, err
+	}
+	token, err := api.newAccessToken(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &authTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(api.accessTTL().Seconds()),
+	}, nil
+}
+
+// setAuthCookies lives as long as the session, expired access token is rejected by the server anyway.
+func (api *APIServer) setAuthCookies(c *gin.Context, tokens *authTokens) {
+	cookie := api.config.Server.Token.Cookie
+	maxAge := int(api.refreshTTL().Seconds())
+	c.SetCookie(cookie.Name, tokens.Token, maxAge, cookie.Path, cookie.Domain, cookie.Secure, cookie.HttpOnly)
+	// Refresh token is sent only to the refresh endpoint.
+	c.SetCookie(api.refreshCookieName(), tokens.RefreshToken, maxAge, refreshCookiePath, cookie.Domain, cookie.Secure, true)
+}
+
+func (api *APIServer) clearAuthCookies(c *gin.Context) {
+	cookie := api.config.Server.Token.Cookie
+	c.SetCookie(cookie.Name, "", -1, cookie.Path, cookie.Domain, false, false)
+	c.SetCookie(api.refreshCookieName(), "", -1, refreshCookiePath, cookie.Domain, false, true)
+}
+
+// decodeAccessToken checks the token signature only, the token may be expired or revoked.
+func (api *APIServer) decodeAccessToken(token string) (payload jwt.Payload, userID, sessionID int64, err error) {
+	if err = api.jwt.Decode(token, &payload); err != nil || payload.Subject == "" || payload.JWTID == "" {
+		return payload, 0, 0, apierror.ErrorInvalidToken
+	}
+	if userID, err = strconv.ParseInt(payload.Subject, 10, 64); err != nil {
+		return payload, 0, 0, apierror.ErrorInvalidToken
+	}
+	if sessionID, err = strconv.ParseInt(payload.JWTID, 10, 64); err != nil {
+		return payload, 0, 0, apierror.ErrorInvalidToken
+	}
+	return payload, userID, sessionID, nil
+}
+
+// authenticate returns apierror.ErrorInvalidToken if the access token is expired or its session is revoked.
+func (api *APIServer) authenticate(token string) (*authorization, error) {
+	payload, userID, sessionID, err := api.decodeAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if payload.ExpirationTime < now.Unix() {
+		return nil, apierror.ErrorInvalidToken
+	}
+	session, err := api.db.GetSession(sessionID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorSessionNotFound) {
+			return nil, apierror.ErrorInvalidToken
+		}
+		return nil, err
+	}
+	if session.UserID != userID || !session.Active(now) {
+		return nil, apierror.ErrorInvalidToken
+	}
+	user, err := api.db.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, apierror.ErrorUserNotFound) {
+			return nil, apierror.ErrorInvalidToken
+		}
+		return nil, err
+	}
+	// Tokens issued before password reset are not valid anymore.
+	if user.SessionsRevokedAt != nil && payload.IssuedAt < user.SessionsRevokedAt.Unix() {
+		return nil, apierror.ErrorInvalidToken
+	}
+	return &authorization{
+		User:      user,
+		Session:   session,
+		ExpiresAt: time.Unix(payload.ExpirationTime, 0),
+	}, nil
+}
+
+// disconnectSessions closes websocket connections of the revoked sessions.
+func (api *APIServer) disconnectSessions(userID int64, sessionIDs ...int64) {
+	for _, sessionID := range sessionIDs {
+		for _, clientID := range api.sessionClients.Clients(sessionID) {
+			if err := api.centrifugeNode.Disconnect(
+				strconv.FormatInt(userID, 10),
+				centrifuge.WithDisconnectClient(clientID),
+				centrifuge.WithCustomDisconnect(centrifuge.DisconnectInvalidToken),
+			); err != nil {
+				api.logger.Error().Err(err).Send()
+			}
+		}
+	}
+}
+
+// clientSessionID returns ID of the session the websocket client is connected with.
+func clientSessionID(c *websocket.Client) int64 {
+	if session, ok := c.GetSession().(*WebsocketSession); ok {
+		return session.SessionID
+	}
+	return 0
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// refreshSession rotates the refresh token. Web clients send it in cookie, mobile clients in body.
+func refreshSession(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshRequest
+		if c.Request.ContentLength > 0 {
+			if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+				c.JSON(http.StatusBadRequest, &apierror.Error{
+					Error: apierror.ErrorBadRequest,
+				})
+				return
+			}
+		}
+		if req.RefreshToken == "" {
+			req.RefreshToken, _ = c.Cookie(api.refreshCookieName())
+		}
+		hash, err := api.tokenSigner.Verify(tokenPurposeRefresh, req.RefreshToken)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
+			return
+		}
+		refreshToken, newHash, err := api.tokenSigner.New(tokenPurposeRefresh)
+		if err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		session, err := api.db.RotateSession(hash, newHash, c.ClientIP(), time.Now().Add(api.refreshTTL()))
+		if err != nil {
+			if errors.Is(err, apierror.ErrorInvalidToken) {
+				// The reused token revoked the session, its clients are disconnected too.
+				if session != nil {
+					api.logger.Warn().Int64("user_id", session.UserID).Int64("session_id", session.ID).Str("ip", c.ClientIP()).Msg("refresh token reuse")
+					api.disconnectSessions(session.UserID, session.ID)
+				}
+				api.clearAuthCookies(c)
+				c.JSON(http.StatusUnauthorized, &apierror.Error{
+					Error: apierror.ErrorInvalidToken,
+				})
+				return
+			}
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		token, err := api.newAccessToken(session.UserID, session.ID)
+		if err != nil {
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		resp := authTokens{
+			Token:        token,
+			RefreshToken: refreshToken,
+			ExpiresIn:    int(api.accessTTL().Seconds()),
+		}
+		api.setAuthCookies(c, &resp)
+		c.JSON(http.StatusOK, &resp)
+	}
+}
+
+// logout revokes the session of the access token, the token may be already expired.
+func logout(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _ := c.Cookie(api.config.Server.Token.Cookie.Name)
+		if _, userID, sessionID, err := api.decodeAccessToken(token); err == nil {
+			if err = api.db.RevokeSession(userID, sessionID); err == nil {
+				api.disconnectSessions(userID, sessionID)
+			} else if !errors.Is(err, apierror.ErrorSessionNotFound) {
+				api.logger.Error().Err(err).Send()
+			}
+		}
+		api.clearAuthCookies(c)
+		c.Redirect(http.StatusFound, "/")
+	}
+}
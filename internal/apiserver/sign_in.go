@@ -3,36 +3,48 @@ package apiserver
 import (
 	"errors"
 	"net/http"
-	"strconv"
 
-	"github.com/armantarkhanian/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type signinRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	// LinkToken is received from OAuth2 callback if the OAuth2 email belongs to this account.
+	LinkToken string `json:"link_token"`
 }
 
 type signinResponse struct {
-	Token string `json:"token"`
+	*authTokens
+	// TwoFactorRequired means the challenge token must be sent with TOTP or recovery code to get the tokens.
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 func signIn(api *APIServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req signinRequest
-		if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+		// Body is already read by the rate limiter.
+		if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 			c.JSON(http.StatusBadRequest, &apierror.Error{
 				Error: apierror.ErrorBadRequest,
 			})
 			return
 		}
+		// Login is locked after repeated wrong passwords, bcrypt is not even run.
+		login := loginKey(req.Login)
+		if retryAfter := api.signInLockout.Check(login); retryAfter > 0 {
+			abortTooManyRequests(c, retryAfter)
+			return
+		}
 		user, err := api.db.GetUserByLogin(req.Login)
 		if err != nil {
 			if errors.Is(err, apierror.ErrorUserNotFound) {
+				api.signInFailed(c, login)
 				c.JSON(http.StatusBadRequest, &apierror.Error{
 					Error: apierror.ErrorUserNotFound,
 				})
@@ -45,43 +57,80 @@ func signIn(api *APIServer) gin.HandlerFunc {
 		}
 		// if user does not have password_bcrypt, it means he signed up using OAuth2 provider, so return error.
 		if user.PasswordBcrypt == nil {
+			api.signInFailed(c, login)
 			c.JSON(http.StatusBadRequest, &apierror.Error{
 				Error: apierror.ErrorInvalidCredentials,
 			})
 			return
 		}
 		if bcrypt.CompareHashAndPassword([]byte(*user.PasswordBcrypt), []byte(req.Password)) != nil {
+			api.signInFailed(c, login)
 			c.JSON(http.StatusBadRequest, &apierror.Error{
 				Error: apierror.ErrorInvalidCredentials,
 			})
 			return
 		}
-
-		jwtToken, err := api.jwt.Encode(jwt.Payload{
-			Subject:        strconv.FormatInt(user.ID, 10),
-			ExpirationTime: int64(api.config.Server.Token.Cookie.MaxAge),
-		})
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, &apierror.Error{
-				Error: apierror.ErrorInternal,
+		api.signInLockout.Success(login)
+		api.signInLoginLimiter.Reset(login)
+		// Password is correct, but the second factor is still required.
+		if user.TwoFactorEnabled {
+			challenge, err := api.newTwoFactorChallenge(user.ID, req.LinkToken)
+			if err != nil {
+				api.logger.Error().Err(err).Send()
+				c.JSON(http.StatusInternalServerError, &apierror.Error{
+					Error: apierror.ErrorInternal,
+				})
+				return
+			}
+			c.JSON(200, &signinResponse{
+				TwoFactorRequired: true,
+				ChallengeToken:    challenge,
 			})
 			return
 		}
+		api.completeSignIn(c, user, req.LinkToken)
+	}
+}
+
+func (api *APIServer) signInFailed(c *gin.Context, login string) {
+	if lockedFor := api.signInLockout.Fail(login); lockedFor > 0 {
+		api.logger.Warn().Str("login", login).Str("ip", c.ClientIP()).Dur("locked_for", lockedFor).Msg("sign in is locked")
+	}
+}
 
-		resp := signinResponse{
-			Token: jwtToken,
+// completeSignIn links the OAuth2 account if user came from OAuth2 callback and creates the session.
+func (api *APIServer) completeSignIn(c *gin.Context, user *model.User, linkToken string) {
+	if linkToken != "" {
+		if err := api.linkOauthWithToken(user.ID, user.Email, linkToken); err != nil {
+			apiErr := apierror.ErrorInternal
+			if !errors.As(err, &apiErr) {
+				api.logger.Error().Err(err).Send()
+				c.JSON(http.StatusInternalServerError, &apierror.Error{
+					Error: apiErr,
+				})
+				return
+			}
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apiErr,
+			})
+			return
 		}
+	}
 
-		c.SetCookie(
-			api.config.Server.Token.Cookie.Name,
-			resp.Token,
-			api.config.Server.Token.Cookie.MaxAge,
-			api.config.Server.Token.Cookie.Path,
-			api.config.Server.Token.Cookie.Domain,
-			api.config.Server.Token.Cookie.Secure,
-			api.config.Server.Token.Cookie.HttpOnly,
-		)
+	tokens, err := api.newSession(c, user.ID)
+	if err != nil {
+		api.logger.Error().Err(err).Send()
+		c.JSON(http.StatusInternalServerError, &apierror.Error{
+			Error: apierror.ErrorInternal,
+		})
+		return
+	}
 
-		c.JSON(200, &resp)
+	resp := signinResponse{
+		authTokens: tokens,
 	}
+
+	api.setAuthCookies(c, tokens)
+
+	c.JSON(200, &resp)
 }
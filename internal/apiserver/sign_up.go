@@ -4,12 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
 
-	"github.com/armantarkhanian/jwt"
 	"github.com/centrifugal/centrifuge"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -25,7 +23,7 @@ type signupRequest struct {
 }
 
 type signupResponse struct {
-	Token string `json:"token"`
+	authTokens
 }
 
 func signUp(api *APIServer) gin.HandlerFunc {
@@ -69,12 +67,14 @@ func signUp(api *APIServer) gin.HandlerFunc {
 			})
 			return
 		}
+		// Sign up doesn't fail if email can't be sent, user can request it again.
+		if err = api.sendVerificationEmail(user); err != nil {
+			api.logger.Error().Err(err).Send()
+		}
 
-		jwtToken, err := api.jwt.Encode(jwt.Payload{
-			Subject:        strconv.FormatInt(user.ID, 10),
-			ExpirationTime: int64(api.config.Server.Token.Cookie.MaxAge),
-		})
+		tokens, err := api.newSession(c, user.ID)
 		if err != nil {
+			api.logger.Error().Err(err).Send()
 			c.JSON(http.StatusInternalServerError, &apierror.Error{
 				Error: apierror.ErrorInternal,
 			})
@@ -82,18 +82,10 @@ func signUp(api *APIServer) gin.HandlerFunc {
 		}
 
 		resp := signupResponse{
-			Token: jwtToken,
+			authTokens: *tokens,
 		}
 
-		c.SetCookie(
-			api.config.Server.Token.Cookie.Name,
-			resp.Token,
-			api.config.Server.Token.Cookie.MaxAge,
-			api.config.Server.Token.Cookie.Path,
-			api.config.Server.Token.Cookie.Domain,
-			api.config.Server.Token.Cookie.Secure,
-			api.config.Server.Token.Cookie.HttpOnly,
-		)
+		api.setAuthCookies(c, tokens)
 
 		c.JSON(200, &resp)
 	}
@@ -121,25 +113,59 @@ func (req *signupRequest) Validate() *centrifuge.Error {
 	if req.RepeatedPassword == "" {
 		return apierror.ErrorRepeatedPasswordIsRequired
 	}
-	usernameLength := utf8.RuneCountInString(req.Username)
+	if err := validateUsername(req.Username); err != nil {
+		return err
+	}
+	if err := validateEmail(req.Email); err != nil {
+		return err
+	}
+	return validatePassword(req.Password, req.RepeatedPassword)
+}
+
+// validateUsername expects trimmed and lowercased username.
+func validateUsername(username string) *centrifuge.Error {
+	if username == "" {
+		return apierror.ErrorUsernameIsRequired
+	}
+	usernameLength := utf8.RuneCountInString(username)
 	if usernameLength < 4 || usernameLength > 32 {
 		return apierror.ErrorInvalidUsernameLength
 	}
-	if !rgxUsername.MatchString(req.Username) {
+	if !rgxUsername.MatchString(username) {
 		return apierror.ErrorInvalidUsernameCharacter
 	}
-	emailLength := utf8.RuneCountInString(req.Email)
+	return nil
+}
+
+// validateEmail expects trimmed and lowercased email.
+func validateEmail(email string) *centrifuge.Error {
+	if email == "" {
+		return apierror.ErrorEmailIsRequired
+	}
+	emailLength := utf8.RuneCountInString(email)
 	if emailLength < 5 || emailLength > 84 {
 		return apierror.ErrorInvalidEmailLength
 	}
-	if strings.Count(req.Email, "@") != 1 {
+	// Line breaks would let the address inject mail headers.
+	if strings.Count(email, "@") != 1 || strings.ContainsAny(email, "\r\n") {
 		return apierror.ErrorInvalidEmail
 	}
-	passwordLength := utf8.RuneCountInString(req.Password)
+	return nil
+}
+
+// validatePassword checks password rules, it's used everywhere a new password is set.
+func validatePassword(password, repeatedPassword string) *centrifuge.Error {
+	if password == "" {
+		return apierror.ErrorPasswordIsRequired
+	}
+	if repeatedPassword == "" {
+		return apierror.ErrorRepeatedPasswordIsRequired
+	}
+	passwordLength := utf8.RuneCountInString(password)
 	if passwordLength < 8 || passwordLength > 64 {
 		return apierror.ErrorInvalidPasswordLength
 	}
-	if !rgxPassword.MatchString(req.Password) {
+	if !rgxPassword.MatchString(password) {
 		return apierror.ErrorInvalidPasswordCharacter
 	}
 
@@ -148,7 +174,7 @@ func (req *signupRequest) Validate() *centrifuge.Error {
 		hasDigit  bool
 	)
 
-	for _, char := range req.Password {
+	for _, char := range password {
 		switch {
 		case unicode.IsLetter(char):
 			hasLetter = true
@@ -166,7 +192,7 @@ func (req *signupRequest) Validate() *centrifuge.Error {
 		return apierror.ErrorMissingDigitInPassword
 	}
 
-	if req.Password != req.RepeatedPassword {
+	if password != repeatedPassword {
 		return apierror.ErrorPasswordsNotEqual
 	}
 
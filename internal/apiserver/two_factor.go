@@ -0,0 +1,167 @@
+package apiserver
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/armantarkhanian/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/renju24/backend/internal/pkg/securetoken"
+	"github.com/renju24/backend/internal/pkg/totp"
+)
+
+const (
+	defaultTwoFactorIssuer       = "Renju24"
+	defaultTwoFactorChallengeTTL = 5 * time.Minute
+	twoFactorChallengeAudience   = "two_factor"
+
+	recoveryCodesCount = 10
+	// recoveryCodeSize bytes are encoded to 16 base32 characters.
+	recoveryCodeSize = 10
+)
+
+var defaultTwoFactorAttempts = config.RateLimitConfig{Requests: 5, Period: 300}
+
+// twoFactorChallenge is issued after the password check. It has no subject, so it can't be used as an auth token.
+type twoFactorChallenge struct {
+	jwt.Payload
+	UserID int64 `json:"user_id"`
+	// LinkToken is passed from sign in to link the OAuth2 account after the second factor.
+	LinkToken string `json:"link_token,omitempty"`
+}
+
+func (api *APIServer) twoFactorIssuer() string {
+	if api.config.TwoFactor.Issuer != "" {
+		return api.config.TwoFactor.Issuer
+	}
+	return defaultTwoFactorIssuer
+}
+
+func (api *APIServer) newTwoFactorChallenge(userID int64, linkToken string) (string, error) {
+	ttl := defaultTwoFactorChallengeTTL
+	if api.config.TwoFactor.ChallengeTTL > 0 {
+		ttl = time.Duration(api.config.TwoFactor.ChallengeTTL) * time.Second
+	}
+	now := time.Now()
+	return api.jwt.Encode(twoFactorChallenge{
+		Payload: jwt.Payload{
+			Audience:       []string{twoFactorChallengeAudience},
+			IssuedAt:       now.Unix(),
+			ExpirationTime: now.Add(ttl).Unix(),
+		},
+		UserID:    userID,
+		LinkToken: linkToken,
+	})
+}
+
+// newRecoveryCodes returns codes to show to user once and their hashes to store.
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	b := make([]byte, recoveryCodeSize)
+	for i := 0; i < recoveryCodesCount; i++ {
+		if _, err = rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		codes = append(codes, code[:8]+"-"+code[8:])
+		hashes = append(hashes, securetoken.Hash(code))
+	}
+	return codes, hashes, nil
+}
+
+// verifySecondFactor accepts the current TOTP code or an unused recovery code.
+func (api *APIServer) verifySecondFactor(userID int64, code string) error {
+	if !api.twoFactorLimiter.Allow(strconv.FormatInt(userID, 10)) {
+		return apierror.ErrorTooManyRequests
+	}
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	if len(code) != totp.Digits {
+		return api.db.UseRecoveryCode(userID, securetoken.Hash(code))
+	}
+	secret, enabled, err := api.db.GetTotpSecret(userID)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return apierror.ErrorTwoFactorNotEnabled
+	}
+	counter, ok := totp.Verify(secret, code, time.Now())
+	if !ok {
+		return apierror.ErrorInvalidTwoFactorCode
+	}
+	// The same code can't be used twice.
+	return api.db.UseTotpCounter(userID, counter)
+}
+
+type signinTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	// Code is TOTP code or recovery code.
+	Code string `json:"code"`
+}
+
+// signInTwoFactor is the second step of sign in for users with two-factor authentication.
+func signInTwoFactor(api *APIServer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req signinTwoFactorRequest
+		if err := c.ShouldBindWith(&req, binding.JSON); err != nil {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorBadRequest,
+			})
+			return
+		}
+		var challenge twoFactorChallenge
+		err := api.jwt.Decode(req.ChallengeToken, &challenge)
+		if err != nil || len(challenge.Audience) != 1 || challenge.Audience[0] != twoFactorChallengeAudience ||
+			challenge.UserID == 0 || challenge.ExpirationTime < time.Now().Unix() {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
+			return
+		}
+		user, err := api.db.GetUserByID(challenge.UserID)
+		if err != nil {
+			if errors.Is(err, apierror.ErrorUserNotFound) {
+				c.JSON(http.StatusBadRequest, &apierror.Error{
+					Error: apierror.ErrorInvalidToken,
+				})
+				return
+			}
+			api.logger.Error().Err(err).Send()
+			c.JSON(http.StatusInternalServerError, &apierror.Error{
+				Error: apierror.ErrorInternal,
+			})
+			return
+		}
+		// Password was reset after the challenge was issued.
+		if user.SessionsRevokedAt != nil && challenge.IssuedAt < user.SessionsRevokedAt.Unix() {
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apierror.ErrorInvalidToken,
+			})
+			return
+		}
+		if err = api.verifySecondFactor(user.ID, req.Code); err != nil {
+			apiErr := apierror.ErrorInternal
+			if !errors.As(err, &apiErr) {
+				api.logger.Error().Err(err).Send()
+				c.JSON(http.StatusInternalServerError, &apierror.Error{
+					Error: apiErr,
+				})
+				return
+			}
+			api.logger.Warn().Int64("user_id", user.ID).Str("ip", c.ClientIP()).Msg("invalid two-factor code")
+			c.JSON(http.StatusBadRequest, &apierror.Error{
+				Error: apiErr,
+			})
+			return
+		}
+		api.completeSignIn(c, user, challenge.LinkToken)
+	}
+}
@@ -2,8 +2,10 @@ package apiserver
 
 import (
 	"encoding/json"
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/armantarkhanian/websocket"
 	"github.com/centrifugal/centrifuge"
@@ -12,13 +14,26 @@ import (
 
 func (*APIServer) OnAlive(*websocket.Client) {}
 
-func (*APIServer) OnDisconect(*websocket.Client, centrifuge.DisconnectEvent) {}
+func (app *APIServer) OnDisconect(c *websocket.Client, _ centrifuge.DisconnectEvent) {
+	if c.Authorized() {
+		app.sessionClients.Remove(clientSessionID(c), c.ID())
+		go app.onUserDisconnect(c.UserID(), c.ID())
+	}
+}
 
 func (app *APIServer) OnSubscribe(c *websocket.Client, e centrifuge.SubscribeEvent) (centrifuge.SubscribeReply, error) {
 	if strings.HasPrefix(e.Channel, "user_") {
 		if e.Channel != "user_"+c.UserID() {
 			return centrifuge.SubscribeReply{}, centrifuge.ErrorPermissionDenied
 		}
+		// Presence in personal channel is used to determine if user is online.
+		app.onUserChannelSubscribe(c.UserID())
+		app.logger.Info().Msgf("user %q subscribed channel %q", c.UserID(), e.Channel)
+		return centrifuge.SubscribeReply{
+			Options: centrifuge.SubscribeOptions{
+				EmitPresence: true,
+			},
+		}, nil
 	}
 
 	if strings.HasPrefix(e.Channel, "game_") {
@@ -50,6 +65,18 @@ func (apiServer *APIServer) OnRPC(c *websocket.Client, rpc centrifuge.RPCEvent)
 	if !c.Authorized() {
 		return centrifuge.RPCReply{}, apierror.ErrorUnauthorized
 	}
+	if ok, retryAfter := apiServer.rpcLimiter.Reserve(c.UserID(), rpc.Method); !ok {
+		if apiServer.rpcLimiter.Violation(c.ID()) {
+			apiServer.logger.Warn().Str("user_id", c.UserID()).Str("method", rpc.Method).Msg("client disconnected for flooding")
+			c.Disconnect(disconnectFlood)
+		}
+		// Centrifuge errors have no fields, so the wait is sent as the event right before the error.
+		apiServer.sendToClient(c, &EventRateLimited{
+			Method:     rpc.Method,
+			RetryAfter: retryAfterSeconds(retryAfter),
+		})
+		return centrifuge.RPCReply{}, apierror.ErrorRateLimited
+	}
 	var response any
 	var err error
 	switch rpc.Method {
@@ -57,6 +84,8 @@ func (apiServer *APIServer) OnRPC(c *websocket.Client, rpc centrifuge.RPCEvent)
 		response, err = apiServer.GetUser(c, rpc.Data)
 	case "game_history":
 		response, err = apiServer.GameHistory(c, rpc.Data)
+	case "game_record":
+		response, err = apiServer.GameRecord(c, rpc.Data)
 	case "find_users":
 		response, err = apiServer.FindUsers(c, rpc.Data)
 	case "call_for_game":
@@ -75,6 +104,76 @@ func (apiServer *APIServer) OnRPC(c *websocket.Client, rpc centrifuge.RPCEvent)
 		response, err = apiServer.BoardState(c, rpc.Data)
 	case "leave_game":
 		response, err = apiServer.LeaveGame(c, rpc.Data)
+	case "send_message":
+		response, err = apiServer.SendMessage(c, rpc.Data)
+	case "chat_history":
+		response, err = apiServer.ChatHistory(c, rpc.Data)
+	case "report_message":
+		response, err = apiServer.ReportMessage(c, rpc.Data)
+	case "list_reports":
+		response, err = apiServer.ListReports(c, rpc.Data)
+	case "resolve_report":
+		response, err = apiServer.ResolveReport(c, rpc.Data)
+	case "delete_message":
+		response, err = apiServer.DeleteMessage(c, rpc.Data)
+	case "mute_user":
+		response, err = apiServer.MuteUser(c, rpc.Data)
+	case "unmute_user":
+		response, err = apiServer.UnmuteUser(c, rpc.Data)
+	case "send_friend_request":
+		response, err = apiServer.SendFriendRequest(c, rpc.Data)
+	case "accept_friend_request":
+		response, err = apiServer.AcceptFriendRequest(c, rpc.Data)
+	case "decline_friend_request":
+		response, err = apiServer.DeclineFriendRequest(c, rpc.Data)
+	case "cancel_friend_request":
+		response, err = apiServer.CancelFriendRequest(c, rpc.Data)
+	case "remove_friend":
+		response, err = apiServer.RemoveFriend(c, rpc.Data)
+	case "friends":
+		response, err = apiServer.Friends(c, rpc.Data)
+	case "block_user":
+		response, err = apiServer.BlockUser(c, rpc.Data)
+	case "unblock_user":
+		response, err = apiServer.UnblockUser(c, rpc.Data)
+	case "blocked_users":
+		response, err = apiServer.BlockedUsers(c, rpc.Data)
+	case "list_notifications":
+		response, err = apiServer.ListNotifications(c, rpc.Data)
+	case "mark_notifications_read":
+		response, err = apiServer.MarkNotificationsRead(c, rpc.Data)
+	case "register_push_subscription":
+		response, err = apiServer.RegisterPushSubscription(c, rpc.Data)
+	case "unregister_push_subscription":
+		response, err = apiServer.UnregisterPushSubscription(c, rpc.Data)
+	case "resend_verification_email":
+		response, err = apiServer.ResendVerificationEmail(c, rpc.Data)
+	case "change_password":
+		response, err = apiServer.ChangePassword(c, rpc.Data)
+	case "change_email":
+		response, err = apiServer.ChangeEmail(c, rpc.Data)
+	case "change_username":
+		response, err = apiServer.ChangeUsername(c, rpc.Data)
+	case "update_profile":
+		response, err = apiServer.UpdateProfile(c, rpc.Data)
+	case "delete_account":
+		response, err = apiServer.DeleteAccount(c, rpc.Data)
+	case "linked_accounts":
+		response, err = apiServer.LinkedAccounts(c, rpc.Data)
+	case "unlink_oauth":
+		response, err = apiServer.UnlinkOauth(c, rpc.Data)
+	case "sessions":
+		response, err = apiServer.Sessions(c, rpc.Data)
+	case "revoke_session":
+		response, err = apiServer.RevokeSession(c, rpc.Data)
+	case "revoke_all_sessions":
+		response, err = apiServer.RevokeAllSessions(c, rpc.Data)
+	case "enable_two_factor":
+		response, err = apiServer.EnableTwoFactor(c, rpc.Data)
+	case "confirm_two_factor":
+		response, err = apiServer.ConfirmTwoFactor(c, rpc.Data)
+	case "disable_two_factor":
+		response, err = apiServer.DisableTwoFactor(c, rpc.Data)
 	default:
 		return centrifuge.RPCReply{}, centrifuge.ErrorMethodNotFound
 	}
@@ -97,8 +196,35 @@ func (*APIServer) OnPublish(*websocket.Client, centrifuge.PublishEvent) (centrif
 	return centrifuge.PublishReply{}, nil
 }
 
-func (*APIServer) OnRefresh(*websocket.Client, centrifuge.RefreshEvent) (centrifuge.RefreshReply, error) {
-	return centrifuge.RefreshReply{}, nil
+// OnRefresh is called when the access token of the connection expires.
+// The connection is prolonged while its session is active, or by the new access token if client sends it.
+func (app *APIServer) OnRefresh(c *websocket.Client, e centrifuge.RefreshEvent) (centrifuge.RefreshReply, error) {
+	if e.ClientSideRefresh {
+		auth, err := app.authenticate(e.Token)
+		if err != nil {
+			if errors.Is(err, apierror.ErrorInvalidToken) {
+				return centrifuge.RefreshReply{Expired: true}, nil
+			}
+			app.logger.Error().Err(err).Send()
+			return centrifuge.RefreshReply{}, centrifuge.ErrorInternal
+		}
+		if strconv.FormatInt(auth.User.ID, 10) != c.UserID() {
+			return centrifuge.RefreshReply{Expired: true}, nil
+		}
+		return centrifuge.RefreshReply{ExpireAt: auth.ExpiresAt.Unix()}, nil
+	}
+	session, err := app.db.GetSession(clientSessionID(c))
+	if err != nil {
+		if errors.Is(err, apierror.ErrorSessionNotFound) {
+			return centrifuge.RefreshReply{Expired: true}, nil
+		}
+		app.logger.Error().Err(err).Send()
+		return centrifuge.RefreshReply{}, centrifuge.ErrorInternal
+	}
+	if !session.Active(time.Now()) {
+		return centrifuge.RefreshReply{Expired: true}, nil
+	}
+	return centrifuge.RefreshReply{ExpireAt: time.Now().Add(app.accessTTL()).Unix()}, nil
 }
 
 func (*APIServer) OnSubRefresh(*websocket.Client, centrifuge.SubRefreshEvent) (centrifuge.SubRefreshReply, error) {
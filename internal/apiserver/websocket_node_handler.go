@@ -5,7 +5,6 @@ import (
 	"errors"
 	"strconv"
 
-	"github.com/armantarkhanian/jwt"
 	"github.com/armantarkhanian/websocket"
 	"github.com/centrifugal/centrifuge"
 	"github.com/renju24/backend/internal/pkg/apierror"
@@ -22,23 +21,16 @@ func (apiServer *APIServer) OnConnecting(_ *centrifuge.Node, e centrifuge.Connec
 		return nil, centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
 	}
 
-	var payload jwt.Payload
-	if err := apiServer.jwt.Decode(e.Token, &payload); err != nil || payload.Subject == "" {
-		return nil, centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
-	}
-
-	userID, err := strconv.ParseInt(payload.Subject, 10, 64)
-	if err != nil {
-		return nil, centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
-	}
-
-	user, err := apiServer.db.GetUserByID(userID)
+	// Expired access tokens and revoked sessions are rejected.
+	auth, err := apiServer.authenticate(e.Token)
 	if err != nil {
-		if errors.Is(err, apierror.ErrorUserNotFound) {
+		if errors.Is(err, apierror.ErrorInvalidToken) {
 			return nil, centrifuge.ConnectReply{}, centrifuge.DisconnectInvalidToken
 		}
+		apiServer.logger.Error().Err(err).Send()
 		return nil, centrifuge.ConnectReply{}, centrifuge.DisconnectServerError
 	}
+	user := auth.User
 
 	b, err := json.Marshal(&RPCGetUserResponse{
 		ID:       user.ID,
@@ -51,16 +43,22 @@ func (apiServer *APIServer) OnConnecting(_ *centrifuge.Node, e centrifuge.Connec
 	}
 
 	websocketSession := WebsocketSession{
-		UserID: userID,
+		UserID:    user.ID,
+		SessionID: auth.Session.ID,
+		ExpireAt:  auth.ExpiresAt.Unix(),
 	}
+	apiServer.sessionClients.Add(auth.Session.ID, e.ClientID)
 
-	apiServer.logger.Info().Int64("user_id", userID).Msg("user connected successfully")
+	apiServer.logger.Info().Int64("user_id", user.ID).Msg("user connected successfully")
 
 	return &websocketSession, centrifuge.ConnectReply{Data: b}, nil
 }
 
 type WebsocketSession struct {
-	UserID int64 `json:"user_id"`
+	UserID    int64 `json:"user_id"`
+	SessionID int64 `json:"session_id"`
+	// ExpireAt is the access token expiration time, the session is checked again on refresh.
+	ExpireAt int64 `json:"expire_at"`
 }
 
 func (ws *WebsocketSession) Authorized() bool {
@@ -69,6 +67,7 @@ func (ws *WebsocketSession) Authorized() bool {
 
 func (ws *WebsocketSession) Credentials() *centrifuge.Credentials {
 	return &centrifuge.Credentials{
-		UserID: strconv.FormatInt(ws.UserID, 10),
+		UserID:   strconv.FormatInt(ws.UserID, 10),
+		ExpireAt: ws.ExpireAt,
 	}
 }
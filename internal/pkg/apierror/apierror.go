@@ -9,6 +9,8 @@ import (
 // Error is the JSON-object that server will return when an error occurs.
 type Error struct {
 	Error *centrifuge.Error `json:"error"`
+	// RetryAfter is the number of seconds to wait before the next request, it's set with ErrorTooManyRequests.
+	RetryAfter int `json:"-"`
 }
 
 type apiErrorJSON struct {
@@ -16,18 +18,20 @@ type apiErrorJSON struct {
 }
 
 type errorJSON struct {
-	Code      uint32 `json:"code"`
-	Message   string `json:"message"`
-	Temporary bool   `json:"temporary"`
+	Code       uint32 `json:"code"`
+	Message    string `json:"message"`
+	Temporary  bool   `json:"temporary"`
+	RetryAfter int    `json:"retry_after,omitempty"`
 }
 
 // MarshalJSON ...
 func (e *Error) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&apiErrorJSON{
 		Error: errorJSON{
-			Code:      e.Error.Code,
-			Message:   e.Error.Message,
-			Temporary: e.Error.Temporary,
+			Code:       e.Error.Code,
+			Message:    e.Error.Message,
+			Temporary:  e.Error.Temporary,
+			RetryAfter: e.RetryAfter,
 		},
 	})
 }
@@ -43,6 +47,7 @@ func (e *Error) UnmarshalJSON(data []byte) error {
 		Message:   apiError.Error.Message,
 		Temporary: apiError.Error.Temporary,
 	}
+	e.RetryAfter = apiError.Error.RetryAfter
 	return nil
 }
 
@@ -82,4 +87,44 @@ var (
 	ErrInvalidTurn                  = &centrifuge.Error{427, "invalid turn", false}
 	ErrRow6IsBannedForBlack         = &centrifuge.Error{428, "black player cannot make row of length 6 and greater", false}
 	ErrInvalidForkForBlack          = &centrifuge.Error{429, "black can make only 3x4 forks", false}
+	ErrorUserIsMuted                = &centrifuge.Error{430, "user is muted", false}
+	ErrorMessageIsEmpty             = &centrifuge.Error{431, "message is empty", false}
+	ErrorMessageIsTooLong           = &centrifuge.Error{432, "message is too long", false}
+	ErrorMessageRejected            = &centrifuge.Error{433, "message contains forbidden words", false}
+	ErrorMessageNotFound            = &centrifuge.Error{434, "message not found", false}
+	ErrorReportNotFound             = &centrifuge.Error{435, "report not found", false}
+	ErrorInvalidMuteDuration        = &centrifuge.Error{436, "invalid mute duration", false}
+	ErrorFriendRequestToYourself    = &centrifuge.Error{437, "can't send friend request to yourself", false}
+	ErrorFriendRequestAlreadySent   = &centrifuge.Error{438, "friend request is already sent", false}
+	ErrorFriendRequestNotFound      = &centrifuge.Error{439, "friend request not found", false}
+	ErrorAlreadyFriends             = &centrifuge.Error{440, "users are already friends", false}
+	ErrorNotFriends                 = &centrifuge.Error{441, "users are not friends", false}
+	ErrorUserIsBlocked              = &centrifuge.Error{442, "interaction with user is blocked", false}
+	ErrorBlockingYourself           = &centrifuge.Error{443, "can't block yourself", false}
+	ErrorInvalidPushSubscription    = &centrifuge.Error{444, "invalid push subscription", false}
+	ErrorInvalidToken               = &centrifuge.Error{445, "invalid or expired token", false}
+	ErrorEmailNotVerified           = &centrifuge.Error{446, "email is not verified", false}
+	ErrorEmailAlreadyVerified       = &centrifuge.Error{447, "email is already verified", false}
+	ErrorTooManyRequests            = &centrifuge.Error{448, "too many requests", true}
+	ErrorUsernameChangeTooSoon      = &centrifuge.Error{449, "username was changed recently", false}
+	ErrorOauthAccountIsTaken        = &centrifuge.Error{450, "oauth account is linked to another user", false}
+	ErrorOauthServiceAlreadyLinked  = &centrifuge.Error{451, "another account of the service is already linked", false}
+	ErrorOauthServiceNotLinked      = &centrifuge.Error{452, "oauth service is not linked", false}
+	ErrorLastLoginMethod            = &centrifuge.Error{453, "can't remove the last login method", false}
+	ErrorSessionNotFound            = &centrifuge.Error{454, "session not found", false}
+	ErrorTwoFactorAlreadyEnabled    = &centrifuge.Error{455, "two-factor authentication is already enabled", false}
+	ErrorTwoFactorNotEnabled        = &centrifuge.Error{456, "two-factor authentication is not enabled", false}
+	ErrorInvalidTwoFactorCode       = &centrifuge.Error{457, "invalid two-factor code", false}
+	ErrorRateLimited                = &centrifuge.Error{458, "rate limit exceeded", true} // Sent after "rate_limited" event with retry_after.
+	ErrorInvalidDisplayName         = &centrifuge.Error{459, "invalid display name", false}
+	ErrorBioIsTooLong               = &centrifuge.Error{460, "bio is too long", false}
+	ErrorInvalidCountry             = &centrifuge.Error{461, "invalid country", false}
+	ErrorInvalidLanguage            = &centrifuge.Error{462, "invalid language", false}
+	ErrorInvalidBoardTheme          = &centrifuge.Error{463, "invalid board theme", false}
+	ErrorInvalidImage               = &centrifuge.Error{464, "invalid image", false}
+	ErrorImageTooLarge              = &centrifuge.Error{465, "image is too large", false}
+	ErrorDeletionConfirmation       = &centrifuge.Error{466, "username confirmation does not match", false}
+	ErrorAccountDeletionDuringGame  = &centrifuge.Error{467, "can't delete account during a game", false}
+	ErrorInvalidBotLevel            = &centrifuge.Error{468, "invalid bot level", false}
+	ErrorReauthenticationRequired   = &centrifuge.Error{469, "sign in again to confirm the change", false}
 )
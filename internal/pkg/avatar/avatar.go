@@ -0,0 +1,169 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var (
+	ErrInvalidImage  = errors.New("avatar: invalid image")
+	ErrImageTooLarge = errors.New("avatar: image is too large")
+)
+
+// Sizes are the sides in pixels of the stored square avatars.
+var Sizes = []int{64, 128, 256}
+
+const (
+	// MaxSide is the maximal width and height of the uploaded image, larger images are rejected before decoding.
+	MaxSide = 4096
+	// MinSide is the minimal width and height of the uploaded image.
+	MinSide = 32
+)
+
+// Store keeps avatars on local disk as <dir>/<user id>/<size>.png.
+type Store struct {
+	dir string
+}
+
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir is served as static files.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Path returns the file path of the avatar relative to the store directory.
+func Path(userID int64, size int) string {
+	return strconv.FormatInt(userID, 10) + "/" + strconv.Itoa(size) + ".png"
+}
+
+// Save decodes PNG, JPEG or GIF image, crops it to the centered square and stores it in all Sizes.
+// Images are re-encoded, so metadata and anything else except pixels are dropped.
+func (s *Store) Save(userID int64, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	if cfg.Width > MaxSide || cfg.Height > MaxSide {
+		return ErrImageTooLarge
+	}
+	if cfg.Width < MinSide || cfg.Height < MinSide {
+		return ErrInvalidImage
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidImage
+	}
+	square := cropSquare(img)
+	userDir := filepath.Join(s.dir, strconv.FormatInt(userID, 10))
+	if err = os.MkdirAll(userDir, 0o755); err != nil {
+		return err
+	}
+	for _, size := range Sizes {
+		var buf bytes.Buffer
+		if err = png.Encode(&buf, resize(square, size)); err != nil {
+			return err
+		}
+		if err = writeFile(filepath.Join(userDir, strconv.Itoa(size)+".png"), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes all sizes of the user's avatar.
+func (s *Store) Delete(userID int64) error {
+	return os.RemoveAll(filepath.Join(s.dir, strconv.FormatInt(userID, 10)))
+}
+
+// writeFile replaces the file atomically, so the avatar being served is never partially written.
+func writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".avatar-*")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("avatar: %w", err)
+	}
+	return nil
+}
+
+// cropSquare returns the centered square of the image with premultiplied RGBA pixels.
+func cropSquare(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	offset := image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2)
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, offset, draw.Src)
+	return square
+}
+
+// resize scales the square image with the box filter, every destination pixel is the average of the source pixels it covers.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	side := src.Bounds().Dx()
+	for y := 0; y < size; y++ {
+		y0, y1 := span(y, size, side)
+		for x := 0; x < size; x++ {
+			x0, x1 := span(x, size, side)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := 0; c < 4; c++ {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8((sum[c] + n/2) / n)
+			}
+		}
+	}
+	return dst
+}
+
+// span returns source range of the destination pixel i, it has at least one pixel when the image is upscaled.
+func span(i, size, side int) (from, to int) {
+	from = i * side / size
+	to = (i + 1) * side / size
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
@@ -0,0 +1,88 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestStoreSave(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	// Wide image: left half is red, right half is blue, so the centered square has both colors.
+	img := image.NewNRGBA(image.Rect(0, 0, 600, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 600; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 300 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	require.NoError(t, store.Save(1, &buf))
+
+	for _, size := range Sizes {
+		f, err := os.Open(filepath.Join(store.Dir(), Path(1, size)))
+		require.NoError(t, err)
+		saved, format, err := image.Decode(f)
+		f.Close()
+		require.NoError(t, err)
+		require.Equal(t, "png", format)
+		require.Equal(t, image.Rect(0, 0, size, size), saved.Bounds())
+
+		r, _, b, _ := saved.At(size/8, size/2).RGBA()
+		require.Greater(t, r, b)
+		r, _, b, _ = saved.At(size-size/8, size/2).RGBA()
+		require.Greater(t, b, r)
+	}
+
+	require.NoError(t, store.Delete(1))
+	_, err := os.Stat(filepath.Join(store.Dir(), "1"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestStoreSaveInvalid(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	require.ErrorIs(t, store.Save(1, strings.NewReader("not an image")), ErrInvalidImage)
+	require.ErrorIs(t, store.Save(1, bytes.NewReader(encodePNG(t, image.NewGray(image.Rect(0, 0, 8, 8))))), ErrInvalidImage)
+	require.ErrorIs(t, store.Save(1, bytes.NewReader(encodePNG(t, image.NewGray(image.Rect(0, 0, MaxSide+1, 64))))), ErrImageTooLarge)
+
+	_, err := os.Stat(filepath.Join(store.Dir(), "1"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestResize(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+	// Average of the first 2x2 block.
+	src.Pix[0] = 0
+	dst := resize(src, 2)
+	require.Equal(t, uint8(150), dst.Pix[0])
+	require.Equal(t, uint8(200), dst.Pix[4])
+
+	// Upscaling repeats pixels.
+	up := resize(src, 8)
+	require.Equal(t, uint8(0), up.Pix[0])
+	require.Equal(t, uint8(0), up.Pix[4])
+	require.Equal(t, uint8(200), up.Pix[8])
+}
@@ -19,10 +19,40 @@ type Config struct {
 			Header struct {
 				Name string `json:"name"`
 			} `json:"header"`
+			// AccessTTL is the lifetime of access token in seconds.
+			AccessTTL int `json:"access_ttl"`
+			// RefreshTTL is the lifetime in seconds of unused session, cookie max age is used by default.
+			RefreshTTL int `json:"refresh_ttl"`
+			// RefreshCookie is the name of refresh token cookie.
+			RefreshCookie string `json:"refresh_cookie"`
 		} `json:"token"`
 	} `json:"server"`
 
 	Oauth2 OauthConfig `json:"oauth2"`
+
+	Chat ChatConfig `json:"chat"`
+
+	WebPush WebPushConfig `json:"web_push"`
+
+	Mail MailConfig `json:"mail"`
+
+	EmailVerification EmailVerificationConfig `json:"email_verification"`
+
+	PasswordReset PasswordResetConfig `json:"password_reset"`
+
+	Account AccountConfig `json:"account"`
+
+	TwoFactor TwoFactorConfig `json:"two_factor"`
+
+	RateLimit RateLimitsConfig `json:"rate_limit"`
+
+	Bot BotConfig `json:"bot"`
+}
+
+type ChatConfig struct {
+	MaxMessageLength int `json:"max_message_length"`
+	// WordLists are forbidden words by locale, messages are checked against all of them.
+	WordLists map[string][]string `json:"word_lists"`
 }
 
 type OauthConfig struct {
@@ -31,6 +61,8 @@ type OauthConfig struct {
 	Yandex    OauthProviderConfig `json:"yandex"`
 	Github    OauthProviderConfig `json:"github"`
 	VK        OauthProviderConfig `json:"vk"`
+	// Custom are OpenID Connect or other OAuth2 providers, they must have name and all URLs.
+	Custom []OauthProviderConfig `json:"custom"`
 }
 
 type OauthProviderConfig struct {
@@ -39,6 +71,24 @@ type OauthProviderConfig struct {
 	Scopes       []string       `json:"scopes"`
 	Callbacks    OauthRedirects `json:"callbacks"`
 	API          string         `json:"api_url"`
+	// AuthURL and TokenURL override the provider's default endpoint.
+	AuthURL  string `json:"auth_url,omitempty"`
+	TokenURL string `json:"token_url,omitempty"`
+	// Name and Logo are required for custom providers.
+	Name string `json:"name,omitempty"`
+	Logo string `json:"logo,omitempty"`
+	// UserInfo overrides the default user info mapping.
+	UserInfo OauthUserInfoConfig `json:"user_info"`
+}
+
+// OauthUserInfoConfig maps user info response to user, fields are dot-separated paths, e.g. "response.0.id".
+type OauthUserInfoConfig struct {
+	ID       string `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// EmailVerified is the boolean claim, e.g. "email_verified", the email is taken only if it's true.
+	// If it's empty, the provider returns only verified emails.
+	EmailVerified string `json:"email_verified,omitempty"`
 }
 
 type OauthRedirects struct {
@@ -46,3 +96,110 @@ type OauthRedirects struct {
 	Android string `json:"android"`
 	IOS     string `json:"ios"`
 }
+
+type WebPushConfig struct {
+	VAPIDPrivateKey string `json:"vapid_private_key"`
+	Subject         string `json:"subject"`
+	TTL             int    `json:"ttl"`
+}
+
+type MailConfig struct {
+	// Transport is one of "smtp", "file" or "log".
+	Transport string `json:"transport"`
+	From      string `json:"from"`
+	SMTP      struct {
+		Host     string `json:"host"`
+		Port     int    `json:"port"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"smtp"`
+	Dir           string `json:"dir"`
+	DefaultLocale string `json:"default_locale"`
+	// BaseURL is used to build links in emails.
+	BaseURL      string `json:"base_url"`
+	MaxAttempts  int    `json:"max_attempts"`
+	PollInterval int    `json:"poll_interval"`
+	// DigestInterval is the period in seconds of emailing unread notifications, zero disables digests.
+	DigestInterval int `json:"digest_interval"`
+}
+
+type EmailVerificationConfig struct {
+	// TokenTTL is the lifetime of verification link in seconds.
+	TokenTTL int `json:"token_ttl"`
+	// ResendInterval is the minimal period in seconds between verification emails.
+	ResendInterval int `json:"resend_interval"`
+	// Forbid unverified users to invite others for a game.
+	RestrictInvitations bool `json:"restrict_invitations"`
+	// Games with unverified users don't change rankings.
+	RestrictRatedGames bool `json:"restrict_rated_games"`
+}
+
+type PasswordResetConfig struct {
+	// TokenTTL is the lifetime of password reset link in seconds.
+	TokenTTL int             `json:"token_ttl"`
+	PerEmail RateLimitConfig `json:"per_email"`
+	PerIP    RateLimitConfig `json:"per_ip"`
+}
+
+// RateLimitConfig allows Requests per Period seconds.
+type RateLimitConfig struct {
+	Requests int `json:"requests"`
+	Period   int `json:"period"`
+}
+
+type AccountConfig struct {
+	// UsernameChangeInterval is the minimal period in days between username changes.
+	UsernameChangeInterval int `json:"username_change_interval"`
+	// UsernameReservation is the period in days the old username can't be taken by others.
+	UsernameReservation int `json:"username_reservation"`
+	// AvatarDir is the directory of uploaded avatars, "./avatars" by default.
+	AvatarDir string `json:"avatar_dir"`
+	// AvatarMaxSize is the maximal upload size in bytes, 5 MB by default.
+	AvatarMaxSize int64 `json:"avatar_max_size"`
+}
+
+type TwoFactorConfig struct {
+	// Issuer is shown in authenticator apps, "Renju24" by default.
+	Issuer string `json:"issuer"`
+	// ChallengeTTL is the time in seconds to enter the code after password, 5 minutes by default.
+	ChallengeTTL int `json:"challenge_ttl"`
+	// Attempts limits codes entered by the user, 5 per 5 minutes by default.
+	Attempts RateLimitConfig `json:"attempts"`
+}
+
+type BotConfig struct {
+	// Username of the system user which plays against users, "renju_bot" by default.
+	Username string `json:"username"`
+	// DefaultLevel is used if user doesn't choose the difficulty, 3 by default.
+	DefaultLevel int `json:"default_level"`
+	// MoveDelay is the minimal time in milliseconds before the bot's move.
+	MoveDelay int `json:"move_delay"`
+}
+
+// RateLimitsConfig protects authentication from brute force and websocket RPC from flooding.
+// Limits which are not configured use the defaults.
+type RateLimitsConfig struct {
+	// AuthPerIP limits sign in and sign up requests from one IP.
+	AuthPerIP RateLimitConfig `json:"auth_per_ip"`
+	// SignInPerLogin limits sign in attempts for one login.
+	SignInPerLogin RateLimitConfig `json:"sign_in_per_login"`
+	Lockout        LockoutConfig   `json:"lockout"`
+	// RPC limits websocket RPC calls of one user.
+	RPC RateLimitConfig `json:"rpc"`
+	// RPCMethods are quotas of the single methods per user, they override the defaults of the expensive methods.
+	RPCMethods map[string]RateLimitConfig `json:"rpc_methods"`
+	// RPCViolations disconnects the client which exceeds the limits more often.
+	RPCViolations RateLimitConfig `json:"rpc_violations"`
+	// AvatarUploads limits avatar uploads of one user.
+	AvatarUploads RateLimitConfig `json:"avatar_uploads"`
+	// DataExports limits personal data exports of one user.
+	DataExports RateLimitConfig `json:"data_exports"`
+}
+
+// LockoutConfig locks the login after Failures wrong passwords for Duration seconds,
+// every next failure doubles the duration up to MaxDuration seconds.
+type LockoutConfig struct {
+	Failures    int `json:"failures"`
+	Duration    int `json:"duration"`
+	MaxDuration int `json:"max_duration"`
+}
@@ -0,0 +1,33 @@
+package config
+
+// redacted replaces the configured secrets in the logged config.
+const redacted = "[REDACTED]"
+
+// Redacted returns the copy of the config which can be logged: secrets are replaced, empty ones are kept
+// to show they are missing.
+func (c Config) Redacted() Config {
+	redact(&c.Server.Token.SigningKey)
+	c.Oauth2.Google = c.Oauth2.Google.redacted()
+	c.Oauth2.Yandex = c.Oauth2.Yandex.redacted()
+	c.Oauth2.Github = c.Oauth2.Github.redacted()
+	c.Oauth2.VK = c.Oauth2.VK.redacted()
+	custom := make([]OauthProviderConfig, len(c.Oauth2.Custom))
+	for i, provider := range c.Oauth2.Custom {
+		custom[i] = provider.redacted()
+	}
+	c.Oauth2.Custom = custom
+	redact(&c.Mail.SMTP.Password)
+	redact(&c.WebPush.VAPIDPrivateKey)
+	return c
+}
+
+func (c OauthProviderConfig) redacted() OauthProviderConfig {
+	redact(&c.ClientSecret)
+	return c
+}
+
+func redact(secret *string) {
+	if *secret != "" {
+		*secret = redacted
+	}
+}
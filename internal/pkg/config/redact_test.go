@@ -0,0 +1,32 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedacted(t *testing.T) {
+	var cfg Config
+	cfg.Server.Token.SigningKey = "signing-key-secret"
+	cfg.Oauth2.Github.ClientSecret = "github-secret"
+	cfg.Oauth2.Custom = []OauthProviderConfig{{Name: "keycloak", ClientSecret: "keycloak-secret"}}
+	cfg.Mail.SMTP.Password = "smtp-secret"
+	cfg.WebPush.VAPIDPrivateKey = "vapid-secret"
+
+	logged, err := json.Marshal(cfg.Redacted())
+	require.NoError(t, err)
+	for _, secret := range []string{"signing-key-secret", "github-secret", "keycloak-secret", "smtp-secret", "vapid-secret"} {
+		require.NotContains(t, string(logged), secret)
+	}
+	require.Contains(t, string(logged), "keycloak")
+	// Missing secrets stay empty.
+	require.Empty(t, cfg.Redacted().Oauth2.Google.ClientSecret)
+
+	// The original config is not changed.
+	require.Equal(t, "github-secret", cfg.Oauth2.Github.ClientSecret)
+	require.Equal(t, "keycloak-secret", cfg.Oauth2.Custom[0].ClientSecret)
+	require.Equal(t, "smtp-secret", cfg.Mail.SMTP.Password)
+	require.Equal(t, "vapid-secret", cfg.WebPush.VAPIDPrivateKey)
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+// UpdatePassword sets the new password, revokes all user's sessions except the given one and tokens issued before revokedAt.
+// It returns IDs of the revoked sessions.
+func (db *Database) UpdatePassword(userID int64, passwordBcrypt string, exceptSessionID int64, revokedAt time.Time) ([]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.Exec(ctx,
+		`UPDATE users SET password_bcrypt = $1, sessions_revoked_at = $2 WHERE id = $3`,
+		passwordBcrypt, revokedAt, userID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	rows, err := tx.Query(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL RETURNING id`,
+		revokedAt, userID, exceptSessionID,
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	var sessionIDs []int64
+	for rows.Next() {
+		var sessionID int64
+		if err = rows.Scan(&sessionID); err != nil {
+			rows.Close()
+			_ = tx.Rollback(ctx)
+			return nil, err
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return sessionIDs, tx.Commit(ctx)
+}
+
+// UpdateEmail changes user's email, the new one has to be verified again.
+func (db *Database) UpdateEmail(userID int64, email string) error {
+	query := `UPDATE users SET email = $1, email_verified = FALSE WHERE id = $2`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if _, err := db.pool.Exec(ctx, query, email, userID); err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.ConstraintName == "unique_email" && pgxErr.Code == pgerrcode.UniqueViolation {
+			return apierror.ErrorEmailIsTaken
+		}
+		return err
+	}
+	return nil
+}
+
+// IsUsernameReserved reports whether the username belonged to another user recently.
+func (db *Database) IsUsernameReserved(username string, userID int64) (bool, error) {
+	query := `SELECT TRUE FROM reserved_usernames WHERE username = $1 AND user_id <> $2 AND reserved_until > NOW()`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var ok bool
+	if err := db.pool.QueryRow(ctx, query, username, userID).Scan(&ok); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return ok, nil
+}
+
+// ChangeUsername renames user if the previous rename was at least changeInterval ago.
+// The old username stays reserved for the user during reservation period.
+func (db *Database) ChangeUsername(userID int64, username string, changeInterval, reservation time.Duration) error {
+	if strings.HasPrefix(username, deletedUsernamePrefix) {
+		return apierror.ErrorUsernameIsTaken
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	var (
+		oldUsername string
+		changedAt   *time.Time
+	)
+	err = tx.QueryRow(ctx, `SELECT username, username_changed_at FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&oldUsername, &changedAt)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apierror.ErrorUserNotFound
+		}
+		return err
+	}
+	if changedAt != nil && time.Since(*changedAt) < changeInterval {
+		_ = tx.Rollback(ctx)
+		return apierror.ErrorUsernameChangeTooSoon
+	}
+	var reserved bool
+	err = tx.QueryRow(ctx,
+		`SELECT TRUE FROM reserved_usernames WHERE username = $1 AND user_id <> $2 AND reserved_until > NOW()`,
+		username, userID,
+	).Scan(&reserved)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if reserved {
+		_ = tx.Rollback(ctx)
+		return apierror.ErrorUsernameIsTaken
+	}
+	if _, err = tx.Exec(ctx, `UPDATE users SET username = $1, username_changed_at = NOW() WHERE id = $2`, username, userID); err != nil {
+		_ = tx.Rollback(ctx)
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.ConstraintName == "unique_username" && pgxErr.Code == pgerrcode.UniqueViolation {
+			return apierror.ErrorUsernameIsTaken
+		}
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM reserved_usernames WHERE username = $1`, username); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if _, err = tx.Exec(ctx, `
+		INSERT INTO reserved_usernames (username, user_id, reserved_until) VALUES ($1, $2, $3)
+		ON CONFLICT (username) DO UPDATE SET user_id = $2, reserved_until = $3;`,
+		oldUsername, userID, time.Now().Add(reservation),
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
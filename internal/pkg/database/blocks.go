@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/model"
+)
+
+// BlockUser also removes friendship and pending friend requests between users.
+func (db *Database) BlockUser(userID, blockedUserID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx,
+		`INSERT INTO blocks (user_id, blocked_user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;`,
+		userID, blockedUserID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if _, err = tx.Exec(ctx,
+		`DELETE FROM friends WHERE (requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1);`,
+		userID, blockedUserID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (db *Database) UnblockUser(userID, blockedUserID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, `DELETE FROM blocks WHERE user_id = $1 AND blocked_user_id = $2`, userID, blockedUserID)
+	return err
+}
+
+// IsBlocked reports whether one of the users has blocked the other one.
+func (db *Database) IsBlocked(userID, otherID int64) (bool, error) {
+	var ok bool
+	query := `
+		SELECT TRUE FROM blocks
+		WHERE (user_id = $1 AND blocked_user_id = $2) OR (user_id = $2 AND blocked_user_id = $1)
+		LIMIT 1`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if err := db.pool.QueryRow(ctx, query, userID, otherID).Scan(&ok); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+	return ok, nil
+}
+
+func (db *Database) GetBlockedUsers(userID int64) ([]*model.User, error) {
+	query := `
+		SELECT
+			u.id,
+			u.username,
+			u.ranking
+		FROM
+			blocks b
+			INNER JOIN users u ON b.blocked_user_id = u.id
+		WHERE b.user_id = $1
+		ORDER BY u.username;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []*model.User
+	for rows.Next() {
+		var user model.User
+		if err = rows.Scan(&user.ID, &user.Username, &user.Ranking); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, err
+}
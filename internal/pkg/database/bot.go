@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/renju24/backend/model"
+)
+
+// GetOrCreateBotUser returns the bot user, it's created on the first start.
+// It fails if the username belongs to a user.
+func (db *Database) GetOrCreateBotUser(username string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if _, err := db.pool.Exec(ctx, `INSERT INTO users (username, is_bot) VALUES ($1, TRUE) ON CONFLICT DO NOTHING`, username); err != nil {
+		return nil, err
+	}
+	var user model.User
+	err := db.pool.QueryRow(ctx, `SELECT id, username, ranking, is_bot FROM users WHERE username = $1`, username).Scan(
+		&user.ID,
+		&user.Username,
+		&user.Ranking,
+		&user.IsBot,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsBot {
+		return nil, fmt.Errorf("database: username %q of the bot belongs to the user %d", username, user.ID)
+	}
+	return &user, nil
+}
+
+// CreateBotGame creates the unrated game against the bot, the game starts immediately.
+func (db *Database) CreateBotGame(blackUserID, whiteUserID int64, botLevel int) (gameID int64, err error) {
+	query := `
+		INSERT INTO games (black_user_id, white_user_id, status, rated, started_at, bot_level)
+		VALUES ($1, $2, $3, FALSE, NOW(), $4) RETURNING id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	err = db.pool.QueryRow(ctx, query, blackUserID, whiteUserID, model.InProgress, botLevel).Scan(&gameID)
+	return gameID, err
+}
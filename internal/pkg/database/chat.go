@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+func (db *Database) CreateMessage(gameID, userID int64, text string) (*model.Message, error) {
+	message := model.Message{
+		GameID: gameID,
+		UserID: userID,
+		Text:   text,
+	}
+	query := `
+		WITH inserted AS (
+			INSERT INTO messages (game_id, user_id, text) VALUES ($1, $2, $3) RETURNING id, user_id, sent_at
+		)
+		SELECT i.id, u.username, i.sent_at FROM inserted i INNER JOIN users u ON i.user_id = u.id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if err := db.pool.QueryRow(ctx, query, gameID, userID, text).Scan(
+		&message.ID,
+		&message.Username,
+		&message.SentAt,
+	); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (db *Database) GetMessageByID(messageID int64) (*model.Message, error) {
+	query := `
+		SELECT
+			m.id,
+			m.game_id,
+			m.user_id,
+			u.username,
+			m.text,
+			m.sent_at
+		FROM
+			messages m
+			INNER JOIN users u ON m.user_id = u.id
+		WHERE m.id = $1 AND m.deleted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var message model.Message
+	err := db.pool.QueryRow(ctx, query, messageID).Scan(
+		&message.ID,
+		&message.GameID,
+		&message.UserID,
+		&message.Username,
+		&message.Text,
+		&message.SentAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierror.ErrorMessageNotFound
+	}
+	return &message, err
+}
+
+func (db *Database) GetGameMessages(gameID int64) ([]model.Message, error) {
+	query := `
+		SELECT
+			m.id,
+			m.game_id,
+			m.user_id,
+			u.username,
+			m.text,
+			m.sent_at
+		FROM
+			messages m
+			INNER JOIN users u ON m.user_id = u.id
+		WHERE m.game_id = $1 AND m.deleted_at IS NULL
+		ORDER BY m.id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, gameID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var messages []model.Message
+	for rows.Next() {
+		var message model.Message
+		if err = rows.Scan(
+			&message.ID,
+			&message.GameID,
+			&message.UserID,
+			&message.Username,
+			&message.Text,
+			&message.SentAt,
+		); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, err
+}
+
+func (db *Database) DeleteMessage(messageID int64) error {
+	query := `UPDATE messages SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, messageID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorMessageNotFound
+	}
+	return nil
+}
+
+// GetActiveMute returns nil if user is not muted right now.
+func (db *Database) GetActiveMute(userID int64) (*model.Mute, error) {
+	query := `SELECT user_id, muted_by, reason, muted_until FROM mutes WHERE user_id = $1 AND muted_until > NOW()`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var mute model.Mute
+	if err := db.pool.QueryRow(ctx, query, userID).Scan(
+		&mute.UserID,
+		&mute.MutedBy,
+		&mute.Reason,
+		&mute.MutedUntil,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &mute, nil
+}
+
+func (db *Database) MuteUser(userID, mutedBy int64, reason string, mutedUntil time.Time) error {
+	query := `
+		INSERT INTO mutes (user_id, muted_by, reason, muted_until) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET muted_by = $2, reason = $3, muted_until = $4;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, userID, mutedBy, reason, mutedUntil)
+	return err
+}
+
+func (db *Database) UnmuteUser(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, `DELETE FROM mutes WHERE user_id = $1`, userID)
+	return err
+}
+
+// CreateReport returns the existing report if user has already reported the message.
+func (db *Database) CreateReport(messageID, reporterID int64, reason string) (reportID int64, err error) {
+	query := `INSERT INTO reports (message_id, reporter_id, reason) VALUES ($1, $2, $3) RETURNING id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if err = db.pool.QueryRow(ctx, query, messageID, reporterID, reason).Scan(&reportID); err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.ConstraintName == "unique_report" && pgxErr.Code == pgerrcode.UniqueViolation {
+			query = `SELECT id FROM reports WHERE message_id = $1 AND reporter_id = $2`
+			err = db.pool.QueryRow(ctx, query, messageID, reporterID).Scan(&reportID)
+			return reportID, err
+		}
+		return 0, err
+	}
+	return reportID, nil
+}
+
+func (db *Database) ListReports(onlyOpen bool) ([]model.Report, error) {
+	query := `
+		SELECT
+			r.id,
+			r.message_id,
+			m.text,
+			author.username,
+			reporter.username,
+			r.reason,
+			r.created_at,
+			r.resolved_at
+		FROM
+			reports r
+			INNER JOIN messages m ON r.message_id = m.id
+			INNER JOIN users author ON m.user_id = author.id
+			INNER JOIN users reporter ON r.reporter_id = reporter.id
+		WHERE NOT $1 OR r.resolved_at IS NULL
+		ORDER BY r.id DESC
+		LIMIT 100;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, onlyOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var reports []model.Report
+	for rows.Next() {
+		var report model.Report
+		if err = rows.Scan(
+			&report.ID,
+			&report.MessageID,
+			&report.MessageText,
+			&report.MessageAuthor,
+			&report.ReporterUsername,
+			&report.Reason,
+			&report.CreatedAt,
+			&report.ResolvedAt,
+		); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, err
+}
+
+func (db *Database) ResolveReport(reportID, resolvedBy int64) error {
+	query := `UPDATE reports SET resolved_by = $1, resolved_at = NOW() WHERE id = $2 AND resolved_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, resolvedBy, reportID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorReportNotFound
+	}
+	return nil
+}
@@ -67,6 +67,13 @@ func (db *Database) CreateUser(username, email, passwordBcrypt string) (*model.U
 		Email:          &email,
 		PasswordBcrypt: &passwordBcrypt,
 	}
+	reserved, err := db.IsUsernameReserved(username, 0)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, apierror.ErrorUsernameIsTaken
+	}
 	query := `INSERT INTO users (username, email, password_bcrypt) VALUES ($1, $2, $3) RETURNING id, ranking;`
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
@@ -93,7 +100,7 @@ func (db *Database) CreateUserOauth(username string, email *string, oauthID stri
 }
 
 func (db *Database) GetUserByLogin(login string) (*model.User, error) {
-	query := "SELECT id, username, email, google_id, yandex_id, ranking, password_bcrypt FROM users "
+	query := "SELECT id, username, email, ranking, password_bcrypt, is_admin, email_verified, totp_enabled, sessions_revoked_at, is_bot FROM users "
 	if strings.Contains(login, "@") {
 		query += "WHERE email = $1"
 	} else {
@@ -106,10 +113,13 @@ func (db *Database) GetUserByLogin(login string) (*model.User, error) {
 		&user.ID,
 		&user.Username,
 		&user.Email,
-		&user.GoogleID,
-		&user.YandexID,
 		&user.Ranking,
 		&user.PasswordBcrypt,
+		&user.IsAdmin,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
+		&user.SessionsRevokedAt,
+		&user.IsBot,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apierror.ErrorUserNotFound
@@ -119,17 +129,20 @@ func (db *Database) GetUserByLogin(login string) (*model.User, error) {
 
 func (db *Database) GetUserByID(userID int64) (*model.User, error) {
 	var user model.User
-	query := `SELECT id, username, email, google_id, yandex_id, ranking, password_bcrypt FROM users WHERE id = $1`
+	query := `SELECT id, username, email, ranking, password_bcrypt, is_admin, email_verified, totp_enabled, sessions_revoked_at, is_bot FROM users WHERE id = $1`
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
 	err := db.pool.QueryRow(ctx, query, userID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
-		&user.GoogleID,
-		&user.YandexID,
 		&user.Ranking,
 		&user.PasswordBcrypt,
+		&user.IsAdmin,
+		&user.EmailVerified,
+		&user.TwoFactorEnabled,
+		&user.SessionsRevokedAt,
+		&user.IsBot,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apierror.ErrorUserNotFound
@@ -137,11 +150,12 @@ func (db *Database) GetUserByID(userID int64) (*model.User, error) {
 	return &user, err
 }
 
-func (db *Database) CreateGame(blackUserID, whiteUserID int64) (gameID int64, err error) {
-	query := `INSERT INTO games (black_user_id, white_user_id, status) VALUES ($1, $2, $3) RETURNING id;`
+// CreateGame creates the game waiting for opponent. Unrated games don't change rankings.
+func (db *Database) CreateGame(blackUserID, whiteUserID int64, rated bool) (gameID int64, err error) {
+	query := `INSERT INTO games (black_user_id, white_user_id, status, rated) VALUES ($1, $2, $3, $4) RETURNING id;`
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
-	if err := db.pool.QueryRow(ctx, query, blackUserID, whiteUserID, model.WaitingOpponent).Scan(&gameID); err != nil {
+	if err := db.pool.QueryRow(ctx, query, blackUserID, whiteUserID, model.WaitingOpponent, rated).Scan(&gameID); err != nil {
 		return 0, err
 	}
 	return gameID, nil
@@ -161,13 +175,23 @@ func (db *Database) IsGameMember(userID, gameID int64) (bool, error) {
 	return ok, nil
 }
 
-func (db *Database) FindUsers(username string) ([]*model.User, error) {
+// FindUsers does not return users blocked by viewer and users who blocked viewer.
+func (db *Database) FindUsers(username string, viewerID int64) ([]*model.User, error) {
 	username = strings.Trim(username, "%")
 	username = "%" + username + "%"
-	query := `SELECT id, username, email, ranking, password_bcrypt FROM users WHERE username ILIKE $1`
+	query := `
+		SELECT u.id, u.username, u.email, u.ranking, u.password_bcrypt
+		FROM users u
+		WHERE
+			u.username ILIKE $1
+			AND u.deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM blocks b
+				WHERE (b.user_id = $2 AND b.blocked_user_id = u.id) OR (b.user_id = u.id AND b.blocked_user_id = $2)
+			)`
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
-	rows, err := db.pool.Query(ctx, query, username)
+	rows, err := db.pool.Query(ctx, query, username, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +245,7 @@ func (db *Database) GameHistory(username string) ([]model.GameHistoryItem, error
 func (db *Database) Top10() ([]*model.User, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
-	rows, err := db.pool.Query(ctx, `SELECT id, username, ranking FROM users ORDER BY ranking DESC LIMIT 10`)
+	rows, err := db.pool.Query(ctx, `SELECT id, username, ranking FROM users WHERE deleted_at IS NULL AND NOT is_bot ORDER BY ranking DESC LIMIT 10`)
 	if err != nil {
 		return nil, err
 	}
@@ -302,8 +326,10 @@ func (db *Database) GetGameByID(gameID int64) (*model.Game, error) {
 			white_user_id,
 			winner_id,
 			status,
+			rated,
 			started_at,
-			finished_at
+			finished_at,
+			bot_level
 		FROM
 			games
 		WHERE id = $1`
@@ -316,8 +342,10 @@ func (db *Database) GetGameByID(gameID int64) (*model.Game, error) {
 		&game.WhiteUserID,
 		&game.Winner,
 		&game.Status,
+		&game.Rated,
 		&game.StartedAt,
 		&game.FinishedAt,
+		&game.BotLevel,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apierror.ErrorGameNotFound
@@ -328,7 +356,7 @@ func (db *Database) GetGameByID(gameID int64) (*model.Game, error) {
 func (db *Database) GetGameMovesByID(gameID int64) ([]model.Move, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
-	rows, err := db.pool.Query(ctx, `SELECT game_id, user_id, x_coordinate, y_coordinate FROM moves WHERE game_id = $1`, gameID)
+	rows, err := db.pool.Query(ctx, `SELECT game_id, user_id, x_coordinate, y_coordinate FROM moves WHERE game_id = $1 ORDER BY id`, gameID)
 	if err != nil {
 		return nil, err
 	}
@@ -364,34 +392,46 @@ func (db *Database) FinishGameWithWinner(gameID, winnerID int64) error {
 		blackRanking int
 		whiteUserID  int64
 		whiteRanking int
+		rated        bool
 	)
 	err = tx.QueryRow(ctx, `
 		SELECT
 			g.black_user_id,
 			black.ranking,
 			g.white_user_id,
-			white.ranking
+			white.ranking,
+			g.rated
 		FROM
 			games g
 			INNER JOIN users black ON g.black_user_id = black.id
 			INNER JOIN users white ON g.white_user_id = white.id
-		WHERE g.id = $1`, gameID).Scan(&blackUserID, &blackRanking, &whiteUserID, &whiteRanking)
+		WHERE g.id = $1`, gameID).Scan(&blackUserID, &blackRanking, &whiteUserID, &whiteRanking, &rated)
 	if err != nil {
 		_ = tx.Rollback(ctx)
 		return err
 	}
-	winnerColor := pkggame.White
-	if blackUserID == winnerID {
-		winnerColor = pkggame.Black
-	}
-	newBlackRating, newWhiteRating := elo.Calculate(blackRanking, whiteRanking, winnerColor)
-	if _, err = tx.Exec(ctx, `UPDATE users SET ranking = $1 WHERE id = $2`, newBlackRating, blackUserID); err != nil {
-		_ = tx.Rollback(ctx)
-		return err
-	}
-	if _, err = tx.Exec(ctx, `UPDATE users SET ranking = $1 WHERE id = $2`, newWhiteRating, whiteUserID); err != nil {
-		_ = tx.Rollback(ctx)
-		return err
+	if rated {
+		winnerColor := pkggame.White
+		if blackUserID == winnerID {
+			winnerColor = pkggame.Black
+		}
+		newBlackRating, newWhiteRating := elo.Calculate(blackRanking, whiteRanking, winnerColor)
+		if _, err = tx.Exec(ctx, `UPDATE users SET ranking = $1 WHERE id = $2`, newBlackRating, blackUserID); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		if _, err = tx.Exec(ctx, `UPDATE users SET ranking = $1 WHERE id = $2`, newWhiteRating, whiteUserID); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+		if _, err = tx.Exec(ctx, `
+			INSERT INTO rating_history (user_id, game_id, rating_before, rating_after)
+			VALUES ($1, $2, $3, $4), ($5, $2, $6, $7)`,
+			blackUserID, gameID, blackRanking, newBlackRating, whiteUserID, whiteRanking, newWhiteRating,
+		); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
 	}
 	if _, err = tx.Exec(ctx,
 		`UPDATE games SET status = $1, winner_id = $2, finished_at = NOW() WHERE id = $3`,
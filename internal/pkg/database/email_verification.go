@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+func (db *Database) CreateEmailVerificationToken(userID int64, email, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO email_verification_tokens (token_hash, user_id, email, expires_at) VALUES ($1, $2, $3, $4);`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, tokenHash, userID, email, expiresAt)
+	return err
+}
+
+// LastEmailVerificationSentAt returns zero time if no verification email was sent to user.
+func (db *Database) LastEmailVerificationSentAt(userID int64) (time.Time, error) {
+	query := `SELECT MAX(created_at) FROM email_verification_tokens WHERE user_id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var sentAt *time.Time
+	if err := db.pool.QueryRow(ctx, query, userID).Scan(&sentAt); err != nil {
+		return time.Time{}, err
+	}
+	if sentAt == nil {
+		return time.Time{}, nil
+	}
+	return *sentAt, nil
+}
+
+// VerifyEmail uses the token and marks user's email as verified.
+// The token is rejected if user has changed email after it was issued.
+func (db *Database) VerifyEmail(tokenHash string) (userID int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var email string
+	err = tx.QueryRow(ctx, `
+		UPDATE email_verification_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id, email`, tokenHash).Scan(&userID, &email)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, apierror.ErrorInvalidToken
+		}
+		return 0, err
+	}
+	tag, err := tx.Exec(ctx, `UPDATE users SET email_verified = TRUE WHERE id = $1 AND email = $2`, userID, email)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return 0, err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return 0, apierror.ErrorInvalidToken
+	}
+	return userID, tx.Commit(ctx)
+}
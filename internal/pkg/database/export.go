@@ -0,0 +1,220 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+// GetGameRecords returns started games of the user with all moves.
+func (db *Database) GetGameRecords(userID int64) ([]*model.GameRecord, error) {
+	return db.getGameRecords(`g.started_at IS NOT NULL AND (g.black_user_id = $1 OR g.white_user_id = $1)`, userID)
+}
+
+// GetFinishedGameRecord returns the finished game with all moves.
+func (db *Database) GetFinishedGameRecord(gameID int64) (*model.GameRecord, error) {
+	games, err := db.getGameRecords(`g.id = $1 AND g.finished_at IS NOT NULL`, gameID)
+	if err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return nil, apierror.ErrorGameNotFound
+	}
+	return games[0], nil
+}
+
+// getGameRecords returns games matching the condition with all moves.
+func (db *Database) getGameRecords(condition string, arg any) ([]*model.GameRecord, error) {
+	query := `
+		SELECT
+			g.id,
+			black.username,
+			white.username,
+			winner.username,
+			g.rated,
+			g.started_at,
+			g.finished_at
+		FROM
+			games g
+			INNER JOIN users black ON g.black_user_id = black.id
+			INNER JOIN users white ON g.white_user_id = white.id
+			LEFT  JOIN users winner ON g.winner_id = winner.id
+		WHERE ` + condition + `
+		ORDER BY g.id`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		games   []*model.GameRecord
+		gameIDs []int64
+		byID    = make(map[int64]*model.GameRecord)
+	)
+	for rows.Next() {
+		var game model.GameRecord
+		if err = rows.Scan(
+			&game.ID,
+			&game.BlackUsername,
+			&game.WhiteUsername,
+			&game.WinnerUsername,
+			&game.Rated,
+			&game.StartedAt,
+			&game.FinishedAt,
+		); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		games = append(games, &game)
+		gameIDs = append(gameIDs, game.ID)
+		byID[game.ID] = &game
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(games) == 0 {
+		return games, nil
+	}
+	rows, err = db.pool.Query(ctx,
+		`SELECT game_id, user_id, x_coordinate, y_coordinate FROM moves WHERE game_id = ANY($1) ORDER BY id`,
+		gameIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var move model.Move
+		if err = rows.Scan(&move.GameID, &move.UserID, &move.XCoordinate, &move.YCoordinate); err != nil {
+			return nil, err
+		}
+		byID[move.GameID].Moves = append(byID[move.GameID].Moves, move)
+	}
+	return games, rows.Err()
+}
+
+// GetUserMessages returns not deleted chat messages written by the user.
+func (db *Database) GetUserMessages(userID int64) ([]model.Message, error) {
+	query := `
+		SELECT m.id, m.game_id, m.user_id, u.username, m.text, m.sent_at
+		FROM messages m INNER JOIN users u ON m.user_id = u.id
+		WHERE m.user_id = $1 AND m.deleted_at IS NULL
+		ORDER BY m.id`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var messages []model.Message
+	for rows.Next() {
+		var message model.Message
+		if err = rows.Scan(&message.ID, &message.GameID, &message.UserID, &message.Username, &message.Text, &message.SentAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+func (db *Database) GetRatingHistory(userID int64) ([]model.RatingChange, error) {
+	query := `
+		SELECT game_id, rating_before, rating_after, created_at
+		FROM rating_history WHERE user_id = $1
+		ORDER BY created_at, game_id`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var history []model.RatingChange
+	for rows.Next() {
+		var change model.RatingChange
+		if err = rows.Scan(&change.GameID, &change.RatingBefore, &change.RatingAfter, &change.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, change)
+	}
+	return history, rows.Err()
+}
+
+// DeleteAccount anonymises the user and removes personal data. Games, moves and rankings of opponents are kept,
+// the games show the user as "deleted-<id>".
+func (db *Database) DeleteAccount(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*4)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	var email *string
+	err = tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, userID).Scan(&email)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apierror.ErrorUserNotFound
+		}
+		return err
+	}
+	if _, err = tx.Exec(ctx, `
+		UPDATE users SET
+			username = $2::TEXT || id,
+			email = NULL,
+			password_bcrypt = NULL,
+			is_admin = FALSE,
+			email_verified = FALSE,
+			sessions_revoked_at = NOW(),
+			username_changed_at = NULL,
+			totp_secret = NULL,
+			totp_enabled = FALSE,
+			totp_last_counter = NULL,
+			display_name = '',
+			bio = '',
+			country = NULL,
+			language = NULL,
+			avatar_updated_at = NULL,
+			deleted_at = NOW()
+		WHERE id = $1`, userID, deletedUsernamePrefix,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	// Message rows are referenced by reports, so only the text is erased.
+	if _, err = tx.Exec(ctx, `UPDATE messages SET text = '', deleted_at = COALESCE(deleted_at, NOW()) WHERE user_id = $1`, userID); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if email != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM email_outbox WHERE recipient = $1 AND sent_at IS NULL`, *email); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+	for _, query := range []string{
+		`DELETE FROM oauth_accounts WHERE user_id = $1`,
+		`DELETE FROM sessions WHERE user_id = $1`,
+		`DELETE FROM recovery_codes WHERE user_id = $1`,
+		`DELETE FROM push_subscriptions WHERE user_id = $1`,
+		`DELETE FROM notifications WHERE user_id = $1`,
+		`DELETE FROM friends WHERE requester_id = $1 OR addressee_id = $1`,
+		`DELETE FROM blocks WHERE user_id = $1 OR blocked_user_id = $1`,
+		`DELETE FROM email_verification_tokens WHERE user_id = $1`,
+		`DELETE FROM password_reset_tokens WHERE user_id = $1`,
+		`DELETE FROM reserved_usernames WHERE user_id = $1`,
+		`DELETE FROM rating_history WHERE user_id = $1`,
+	} {
+		if _, err = tx.Exec(ctx, query, userID); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
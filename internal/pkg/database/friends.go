@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+// GetFriendshipStatus returns the friendship status from the point of view of userID.
+func (db *Database) GetFriendshipStatus(userID, otherID int64) (model.FriendshipStatus, error) {
+	query := `
+		SELECT
+			requester_id,
+			accepted_at IS NOT NULL
+		FROM
+			friends
+		WHERE
+			(requester_id = $1 AND addressee_id = $2)
+			OR (requester_id = $2 AND addressee_id = $1)`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var (
+		requesterID int64
+		accepted    bool
+	)
+	if err := db.pool.QueryRow(ctx, query, userID, otherID).Scan(&requesterID, &accepted); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.NotFriends, nil
+		}
+		return model.NotFriends, err
+	}
+	switch {
+	case accepted:
+		return model.Friends, nil
+	case requesterID == userID:
+		return model.RequestSent, nil
+	default:
+		return model.RequestReceived, nil
+	}
+}
+
+func (db *Database) CreateFriendRequest(requesterID, addresseeID int64) error {
+	query := `INSERT INTO friends (requester_id, addressee_id) VALUES ($1, $2);`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if _, err := db.pool.Exec(ctx, query, requesterID, addresseeID); err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.Code == pgerrcode.UniqueViolation {
+			return apierror.ErrorFriendRequestAlreadySent
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *Database) AcceptFriendRequest(requesterID, addresseeID int64) error {
+	query := `UPDATE friends SET accepted_at = NOW() WHERE requester_id = $1 AND addressee_id = $2 AND accepted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, requesterID, addresseeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorFriendRequestNotFound
+	}
+	return nil
+}
+
+// DeleteFriendRequest removes a pending friend request, it's used both for declining and cancelling.
+func (db *Database) DeleteFriendRequest(requesterID, addresseeID int64) error {
+	query := `DELETE FROM friends WHERE requester_id = $1 AND addressee_id = $2 AND accepted_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, requesterID, addresseeID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorFriendRequestNotFound
+	}
+	return nil
+}
+
+func (db *Database) RemoveFriend(userID, friendID int64) error {
+	query := `
+		DELETE FROM friends
+		WHERE
+			((requester_id = $1 AND addressee_id = $2) OR (requester_id = $2 AND addressee_id = $1))
+			AND accepted_at IS NOT NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, userID, friendID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorNotFriends
+	}
+	return nil
+}
+
+func (db *Database) GetFriends(userID int64) ([]*model.User, error) {
+	query := `
+		SELECT
+			u.id,
+			u.username,
+			u.ranking
+		FROM
+			friends f
+			INNER JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE
+			(f.requester_id = $1 OR f.addressee_id = $1)
+			AND f.accepted_at IS NOT NULL
+		ORDER BY u.username;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []*model.User
+	for rows.Next() {
+		var user model.User
+		if err = rows.Scan(&user.ID, &user.Username, &user.Ranking); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+	return users, err
+}
+
+// GetFriendRequests returns both incoming and outgoing pending friend requests.
+func (db *Database) GetFriendRequests(userID int64) ([]model.FriendRequest, error) {
+	query := `
+		SELECT
+			u.id,
+			u.username,
+			u.ranking,
+			f.addressee_id = $1,
+			f.created_at
+		FROM
+			friends f
+			INNER JOIN users u ON u.id = CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END
+		WHERE
+			(f.requester_id = $1 OR f.addressee_id = $1)
+			AND f.accepted_at IS NULL
+		ORDER BY f.created_at DESC;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var requests []model.FriendRequest
+	for rows.Next() {
+		var request model.FriendRequest
+		if err = rows.Scan(
+			&request.UserID,
+			&request.Username,
+			&request.Ranking,
+			&request.Incoming,
+			&request.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+	return requests, err
+}
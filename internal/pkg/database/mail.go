@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/renju24/backend/internal/pkg/mailer"
+	"github.com/renju24/backend/model"
+)
+
+func (db *Database) EnqueueEmail(msg *mailer.Message) error {
+	query := `INSERT INTO email_outbox (recipient, subject, text_body, html_body) VALUES ($1, $2, $3, $4) RETURNING id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	return db.pool.QueryRow(ctx, query, msg.To, msg.Subject, msg.Text, msg.HTML).Scan(&msg.ID)
+}
+
+// ClaimEmails moves next_attempt_at of the due messages by the lease, so concurrent senders skip them.
+func (db *Database) ClaimEmails(limit int, lease time.Duration) ([]*mailer.Message, error) {
+	query := `
+		UPDATE email_outbox SET next_attempt_at = NOW() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM email_outbox
+			WHERE sent_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, recipient, subject, text_body, html_body, attempts;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var messages []*mailer.Message
+	for rows.Next() {
+		var msg mailer.Message
+		if err = rows.Scan(&msg.ID, &msg.To, &msg.Subject, &msg.Text, &msg.HTML, &msg.Attempts); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &msg)
+	}
+	return messages, rows.Err()
+}
+
+func (db *Database) MarkEmailSent(id int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, `UPDATE email_outbox SET sent_at = NOW(), next_attempt_at = NULL WHERE id = $1`, id)
+	return err
+}
+
+// MarkEmailFailed stops retrying if nextAttemptAt is zero.
+func (db *Database) MarkEmailFailed(id int64, sendErr error, nextAttemptAt time.Time) error {
+	var next *time.Time
+	if !nextAttemptAt.IsZero() {
+		next = &nextAttemptAt
+	}
+	query := `UPDATE email_outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, sendErr.Error(), next, id)
+	return err
+}
+
+// NotificationDigests returns users with email who have unread notifications older than olderThan
+// which were not included in a digest yet.
+func (db *Database) NotificationDigests(olderThan time.Time) ([]model.NotificationDigest, error) {
+	query := `
+		SELECT
+			u.id,
+			u.username,
+			u.email,
+			u.language,
+			COUNT(*)
+		FROM
+			notifications n
+			INNER JOIN users u ON n.user_id = u.id
+		WHERE
+			n.read_at IS NULL
+			AND n.digested_at IS NULL
+			AND n.created_at < $1
+			AND u.email IS NOT NULL
+		GROUP BY u.id, u.username, u.email, u.language;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, olderThan)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var digests []model.NotificationDigest
+	for rows.Next() {
+		var digest model.NotificationDigest
+		if err = rows.Scan(&digest.UserID, &digest.Username, &digest.Email, &digest.Language, &digest.UnreadCount); err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+	}
+	return digests, err
+}
+
+func (db *Database) MarkNotificationsDigested(userID int64, olderThan time.Time) error {
+	query := `UPDATE notifications SET digested_at = NOW() WHERE user_id = $1 AND digested_at IS NULL AND created_at < $2`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, userID, olderThan)
+	return err
+}
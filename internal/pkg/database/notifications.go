@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+
+	"github.com/renju24/backend/model"
+)
+
+func (db *Database) CreateNotification(userID int64, eventType string, data []byte) (*model.Notification, error) {
+	notification := model.Notification{
+		UserID:    userID,
+		EventType: eventType,
+		Data:      data,
+	}
+	query := `INSERT INTO notifications (user_id, event_type, data) VALUES ($1, $2, $3) RETURNING id, created_at;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if err := db.pool.QueryRow(ctx, query, userID, eventType, data).Scan(
+		&notification.ID,
+		&notification.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// ListNotifications returns notifications older than beforeID from newest to oldest.
+// Zero beforeID means from the newest one.
+func (db *Database) ListNotifications(userID int64, unreadOnly bool, beforeID int64, limit int) ([]model.Notification, error) {
+	query := `
+		SELECT
+			id,
+			user_id,
+			event_type,
+			data,
+			created_at,
+			read_at
+		FROM
+			notifications
+		WHERE
+			user_id = $1
+			AND (NOT $2 OR read_at IS NULL)
+			AND ($3 = 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID, unreadOnly, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var notifications []model.Notification
+	for rows.Next() {
+		var notification model.Notification
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.UserID,
+			&notification.EventType,
+			&notification.Data,
+			&notification.CreatedAt,
+			&notification.ReadAt,
+		); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, err
+}
+
+func (db *Database) CountUnreadNotifications(userID int64) (count int, err error) {
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	err = db.pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// MarkNotificationsRead marks all user's notifications as read if notificationIDs is empty.
+func (db *Database) MarkNotificationsRead(userID int64, notificationIDs []int64) error {
+	query := `
+		UPDATE notifications SET read_at = NOW()
+		WHERE user_id = $1 AND read_at IS NULL AND (cardinality($2::INT[]) = 0 OR id = ANY($2))`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	if notificationIDs == nil {
+		notificationIDs = []int64{}
+	}
+	_, err := db.pool.Exec(ctx, query, userID, notificationIDs)
+	return err
+}
@@ -15,36 +15,65 @@ import (
 	"github.com/renju24/backend/model"
 )
 
+// deletedUsernamePrefix is the prefix of usernames of deleted accounts, see DeleteAccount.
+// Nobody else can take such username, otherwise deletion of the account would violate unique_username.
+const deletedUsernamePrefix = "deleted-"
+
+// oauthUsername replaces the prefix of deleted accounts in the username received from OAuth2 provider.
+// The length is kept, so the username still fits the column.
+func oauthUsername(username string) string {
+	if strings.HasPrefix(username, deletedUsernamePrefix) {
+		return "deleted_" + strings.TrimPrefix(username, deletedUsernamePrefix)
+	}
+	return username
+}
+
 func (db *Database) createUserOauth(username string, email *string, oauthID string, service oauth.Service) (*model.User, error) {
-	query := `INSERT INTO users (username, email, %s) VALUES ($1, $2, $3) RETURNING id, ranking;`
-	switch service {
-	case oauth.Google:
-		query = fmt.Sprintf(query, "google_id")
-	case oauth.Yandex:
-		query = fmt.Sprintf(query, "yandex_id")
-	case oauth.VK:
-		query = fmt.Sprintf(query, "vk_id")
-	default:
-		return nil, oauth.ErrUnknownService
+	// User has already signed up with this OAuth2 account.
+	user, err := db.getUserByOauthUserID(oauthID, service)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, apierror.ErrorUserNotFound) {
+		return nil, err
+	}
+	username = oauthUsername(username)
+	// Recently released username is reserved like the taken one, so the suffix is added.
+	reserved, err := db.IsUsernameReserved(username, 0)
+	if err != nil {
+		return nil, err
 	}
-	user := model.User{
-		Username: username,
-		Email:    email,
-		GoogleID: &oauthID,
+	for i := 1; reserved; i++ {
+		candidate := fmt.Sprintf("%s-%d", username, i)
+		if reserved, err = db.IsUsernameReserved(candidate, 0); err != nil {
+			return nil, err
+		}
+		if !reserved {
+			username = candidate
+		}
+	}
+	// Emails received from OAuth2 providers are already verified by them.
+	query := `
+		WITH new_user AS (
+			INSERT INTO users (username, email, email_verified) VALUES ($1, $2, $3) RETURNING id, ranking
+		), new_account AS (
+			INSERT INTO oauth_accounts (user_id, service, oauth_id) SELECT id, $4, $5 FROM new_user
+		)
+		SELECT id, ranking FROM new_user;`
+	user = &model.User{
+		Username:      username,
+		Email:         email,
+		EmailVerified: email != nil,
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
-	if err := db.pool.QueryRow(ctx, query, username, email, oauthID).Scan(&user.ID, &user.Ranking); err != nil {
+	if err := db.pool.QueryRow(ctx, query, username, email, email != nil, service, oauthID).Scan(&user.ID, &user.Ranking); err != nil {
 		var pgxErr *pgconn.PgError
 		if errors.As(err, &pgxErr) {
 			if pgxErr.Code == pgerrcode.UniqueViolation {
 				switch pgxErr.ConstraintName {
-				case "unique_google_id":
-					return db.getUserByOauthUserID(oauthID, oauth.Google)
-				case "unique_yandex_id":
-					return db.getUserByOauthUserID(oauthID, oauth.Yandex)
-				case "unique_vk_id":
-					return db.getUserByOauthUserID(oauthID, oauth.VK)
+				case "unique_oauth_account":
+					return db.getUserByOauthUserID(oauthID, service)
 				case "unique_username":
 					// if username is already taken, then select last username and increment it.
 					var lastUsername string
@@ -65,40 +94,112 @@ func (db *Database) createUserOauth(username string, email *string, oauthID stri
 					username = fmt.Sprintf("%s-%d", username, i+1)
 					return db.createUserOauth(username, email, oauthID, service)
 				case "unique_email":
-					// TODO: what to do if email is already taken when using OAuth2 authorization.
+					// User has to sign in to the existing account to link the OAuth2 account.
 					return nil, apierror.ErrorEmailIsTaken
 				}
 			}
 		}
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
 func (db *Database) getUserByOauthUserID(oauthID string, service oauth.Service) (*model.User, error) {
-	query := `SELECT id, username, email, ranking, password_bcrypt FROM users WHERE %s = $1`
-	switch service {
-	case oauth.Google:
-		query = fmt.Sprintf(query, "google_id")
-	case oauth.Yandex:
-		query = fmt.Sprintf(query, "yandex_id")
-	case oauth.VK:
-		query = fmt.Sprintf(query, "vk_id")
-	default:
-		return nil, oauth.ErrUnknownService
-	}
+	query := `
+		SELECT users.id, users.username, users.email, users.ranking, users.password_bcrypt, users.totp_enabled
+		FROM oauth_accounts JOIN users ON users.id = oauth_accounts.user_id
+		WHERE oauth_accounts.service = $1 AND oauth_accounts.oauth_id = $2`
 	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
 	defer cancel()
 	var user model.User
-	err := db.pool.QueryRow(ctx, query, oauthID).Scan(
+	err := db.pool.QueryRow(ctx, query, service, oauthID).Scan(
 		&user.ID,
 		&user.Username,
 		&user.Email,
 		&user.Ranking,
 		&user.PasswordBcrypt,
+		&user.TwoFactorEnabled,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, apierror.ErrorUserNotFound
 	}
 	return &user, err
 }
+
+// LinkOauth adds OAuth2 account to the existing user.
+func (db *Database) LinkOauth(userID int64, oauthID string, service oauth.Service) error {
+	query := `
+		INSERT INTO oauth_accounts (user_id, service, oauth_id) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, service) DO NOTHING`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, userID, service, oauthID)
+	if err != nil {
+		var pgxErr *pgconn.PgError
+		if errors.As(err, &pgxErr) && pgxErr.ConstraintName == "unique_oauth_account" && pgxErr.Code == pgerrcode.UniqueViolation {
+			// Linking the same account again is not an error.
+			var linkedUserID int64
+			err = db.pool.QueryRow(ctx, `SELECT user_id FROM oauth_accounts WHERE service = $1 AND oauth_id = $2`, service, oauthID).Scan(&linkedUserID)
+			if err == nil && linkedUserID == userID {
+				return nil
+			}
+			return apierror.ErrorOauthAccountIsTaken
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var linkedID string
+		err = db.pool.QueryRow(ctx, `SELECT oauth_id FROM oauth_accounts WHERE user_id = $1 AND service = $2`, userID, service).Scan(&linkedID)
+		if err == nil && linkedID == oauthID {
+			return nil
+		}
+		return apierror.ErrorOauthServiceAlreadyLinked
+	}
+	return nil
+}
+
+// UnlinkOauth removes OAuth2 account from user if user can still sign in with password or another OAuth2 account.
+func (db *Database) UnlinkOauth(userID int64, service oauth.Service) error {
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM oauth_accounts WHERE user_id = users.id AND service = $2),
+			password_bcrypt IS NOT NULL OR EXISTS (SELECT 1 FROM oauth_accounts WHERE user_id = users.id AND service <> $2)
+		FROM users WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var linked, hasOtherMethod bool
+	if err := db.pool.QueryRow(ctx, query, userID, service).Scan(&linked, &hasOtherMethod); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return apierror.ErrorUserNotFound
+		}
+		return err
+	}
+	if !linked {
+		return apierror.ErrorOauthServiceNotLinked
+	}
+	if !hasOtherMethod {
+		return apierror.ErrorLastLoginMethod
+	}
+	_, err := db.pool.Exec(ctx, `DELETE FROM oauth_accounts WHERE user_id = $1 AND service = $2`, userID, service)
+	return err
+}
+
+// GetOauthServices returns services linked to the user.
+func (db *Database) GetOauthServices(userID int64) ([]oauth.Service, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, `SELECT service FROM oauth_accounts WHERE user_id = $1 ORDER BY created_at, service`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	services := []oauth.Service{}
+	for rows.Next() {
+		var service oauth.Service
+		if err = rows.Scan(&service); err != nil {
+			return nil, err
+		}
+		services = append(services, service)
+	}
+	return services, rows.Err()
+}
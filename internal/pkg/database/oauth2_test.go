@@ -0,0 +1,30 @@
+package database
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOauthUsername(t *testing.T) {
+	testCases := []struct {
+		username string
+		expected string
+	}{
+		{username: "octocat", expected: "octocat"},
+		{username: "mona-lisa", expected: "mona-lisa"},
+		// GitHub user named after the deleted account would block its deletion.
+		{username: "deleted-12", expected: "deleted_12"},
+		{username: "deleted-", expected: "deleted_"},
+		{username: "undeleted-12", expected: "undeleted-12"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.username, func(t *testing.T) {
+			username := oauthUsername(tc.username)
+			require.Equal(t, tc.expected, username)
+			require.False(t, strings.HasPrefix(username, deletedUsernamePrefix))
+			require.Equal(t, len(tc.username), len(username))
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+func (db *Database) CreatePasswordResetToken(userID int64, tokenHash string, expiresAt time.Time) error {
+	query := `INSERT INTO password_reset_tokens (token_hash, user_id, expires_at) VALUES ($1, $2, $3);`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, tokenHash, userID, expiresAt)
+	return err
+}
+
+// ResetPassword uses the token, sets the new password and revokes all sessions and tokens issued before revokedAt.
+// Other unused reset tokens of the user are invalidated too.
+func (db *Database) ResetPassword(tokenHash, passwordBcrypt string, revokedAt time.Time) (userID int64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`, tokenHash).Scan(&userID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, apierror.ErrorInvalidToken
+		}
+		return 0, err
+	}
+	if _, err = tx.Exec(ctx,
+		`UPDATE password_reset_tokens SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`,
+		userID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return 0, err
+	}
+	if _, err = tx.Exec(ctx,
+		`UPDATE users SET password_bcrypt = $1, sessions_revoked_at = $2 WHERE id = $3`,
+		passwordBcrypt, revokedAt, userID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return 0, err
+	}
+	if _, err = tx.Exec(ctx,
+		`UPDATE sessions SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`,
+		revokedAt, userID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return 0, err
+	}
+	return userID, tx.Commit(ctx)
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+func (db *Database) GetProfile(userID int64) (*model.Profile, error) {
+	query := `
+		SELECT display_name, bio, country, avatar_updated_at, language, board_theme, board_coordinates, move_numbers
+		FROM users WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var profile model.Profile
+	err := db.pool.QueryRow(ctx, query, userID).Scan(
+		&profile.DisplayName,
+		&profile.Bio,
+		&profile.Country,
+		&profile.AvatarUpdatedAt,
+		&profile.Settings.Language,
+		&profile.Settings.BoardTheme,
+		&profile.Settings.BoardCoordinates,
+		&profile.Settings.MoveNumbers,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierror.ErrorUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateProfile saves profile and settings, the avatar is changed by SetAvatarUpdatedAt.
+func (db *Database) UpdateProfile(userID int64, profile *model.Profile) error {
+	query := `
+		UPDATE users SET
+			display_name = $1, bio = $2, country = $3,
+			language = $4, board_theme = $5, board_coordinates = $6, move_numbers = $7
+		WHERE id = $8`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query,
+		profile.DisplayName,
+		profile.Bio,
+		profile.Country,
+		profile.Settings.Language,
+		profile.Settings.BoardTheme,
+		profile.Settings.BoardCoordinates,
+		profile.Settings.MoveNumbers,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorUserNotFound
+	}
+	return nil
+}
+
+// SetAvatarUpdatedAt is called after the avatar files are saved, nil means user has deleted the avatar.
+func (db *Database) SetAvatarUpdatedAt(userID int64, updatedAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, `UPDATE users SET avatar_updated_at = $1 WHERE id = $2`, updatedAt, userID)
+	return err
+}
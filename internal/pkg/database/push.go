@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+
+	"github.com/renju24/backend/internal/pkg/webpush"
+)
+
+// SavePushSubscription moves the subscription to the user if the endpoint is already registered,
+// because the same browser can be used by another account.
+func (db *Database) SavePushSubscription(userID int64, sub *webpush.Subscription) error {
+	query := `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE SET user_id = $1, p256dh = $3, auth = $4;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, query, userID, sub.Endpoint, sub.Keys.P256dh, sub.Keys.Auth)
+	return err
+}
+
+func (db *Database) DeletePushSubscription(userID int64, endpoint string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	_, err := db.pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`, userID, endpoint)
+	return err
+}
+
+func (db *Database) GetPushSubscriptions(userID int64) ([]*webpush.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, `SELECT endpoint, p256dh, auth FROM push_subscriptions WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var subs []*webpush.Subscription
+	for rows.Next() {
+		var sub webpush.Subscription
+		if err = rows.Scan(&sub.Endpoint, &sub.Keys.P256dh, &sub.Keys.Auth); err != nil {
+			return nil, err
+		}
+		subs = append(subs, &sub)
+	}
+	return subs, err
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+	"github.com/renju24/backend/model"
+)
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row pgx.Row) (*model.Session, error) {
+	var session model.Session
+	err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastUsedAt,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+	)
+	return &session, err
+}
+
+func (db *Database) CreateSession(userID int64, tokenHash, userAgent, ip string, expiresAt time.Time) (sessionID int64, err error) {
+	query := `INSERT INTO sessions (user_id, token_hash, user_agent, ip, expires_at) VALUES ($1, $2, $3, $4, $5) RETURNING id;`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	err = db.pool.QueryRow(ctx, query, userID, tokenHash, truncate(userAgent, 256), truncate(ip, 45), expiresAt).Scan(&sessionID)
+	return sessionID, err
+}
+
+func (db *Database) GetSession(sessionID int64) (*model.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	session, err := scanSession(db.pool.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, sessionID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apierror.ErrorSessionNotFound
+	}
+	return session, err
+}
+
+// RotateSession replaces the refresh token of the active session and prolongs it.
+// If the token has already been rotated, it's probably stolen, so the session is revoked.
+func (db *Database) RotateSession(tokenHash, newTokenHash, ip string, expiresAt time.Time) (*model.Session, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session, err := scanSession(tx.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE token_hash = $1 FOR UPDATE`, tokenHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		var reused *model.Session
+		reused, err = scanSession(tx.QueryRow(ctx, `
+			UPDATE sessions SET revoked_at = COALESCE(revoked_at, NOW())
+			WHERE previous_token_hash = $1
+			RETURNING `+sessionColumns, tokenHash))
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, apierror.ErrorInvalidToken
+			}
+			return nil, err
+		}
+		if err = tx.Commit(ctx); err != nil {
+			return nil, err
+		}
+		return reused, apierror.ErrorInvalidToken
+	}
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	if !session.Active(time.Now()) {
+		_ = tx.Rollback(ctx)
+		return nil, apierror.ErrorInvalidToken
+	}
+	if _, err = tx.Exec(ctx, `
+		UPDATE sessions
+		SET previous_token_hash = token_hash, token_hash = $1, ip = $2, last_used_at = NOW(), expires_at = $3
+		WHERE id = $4`,
+		newTokenHash, truncate(ip, 45), expiresAt, session.ID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	session.IP = ip
+	session.ExpiresAt = expiresAt
+	return session, tx.Commit(ctx)
+}
+
+// GetActiveSessions returns user's sessions, the recently used first.
+func (db *Database) GetActiveSessions(userID int64) ([]*model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC, id DESC`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := []*model.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (db *Database) RevokeSession(userID, sessionID int64) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorSessionNotFound
+	}
+	return nil
+}
+
+// RevokeSessions revokes all user's sessions except the given one and returns IDs of the revoked sessions.
+func (db *Database) RevokeSessions(userID, exceptSessionID int64) ([]int64, error) {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	rows, err := db.pool.Query(ctx, query, userID, exceptSessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessionIDs []int64
+	for rows.Next() {
+		var sessionID int64
+		if err = rows.Scan(&sessionID); err != nil {
+			return nil, err
+		}
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	return sessionIDs, rows.Err()
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return strings.ToValidUTF8(s[:n], "")
+	}
+	return s
+}
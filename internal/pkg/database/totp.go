@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/renju24/backend/internal/pkg/apierror"
+)
+
+// SetTotpSecret saves the secret which is not active until it's confirmed with a code.
+func (db *Database) SetTotpSecret(userID int64, secret string) error {
+	query := `UPDATE users SET totp_secret = $1 WHERE id = $2 AND NOT totp_enabled`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, secret, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorTwoFactorAlreadyEnabled
+	}
+	return nil
+}
+
+// GetTotpSecret returns apierror.ErrorTwoFactorNotEnabled if user hasn't started enrolment.
+func (db *Database) GetTotpSecret(userID int64) (secret string, enabled bool, err error) {
+	query := `SELECT totp_secret, totp_enabled FROM users WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	var nullableSecret *string
+	if err = db.pool.QueryRow(ctx, query, userID).Scan(&nullableSecret, &enabled); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, apierror.ErrorUserNotFound
+		}
+		return "", false, err
+	}
+	if nullableSecret == nil {
+		return "", false, apierror.ErrorTwoFactorNotEnabled
+	}
+	return *nullableSecret, enabled, nil
+}
+
+// EnableTotp activates the secret and replaces recovery codes.
+func (db *Database) EnableTotp(userID, counter int64, recoveryCodeHashes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx,
+		`UPDATE users SET totp_enabled = TRUE, totp_last_counter = $1 WHERE id = $2 AND NOT totp_enabled AND totp_secret IS NOT NULL`,
+		counter, userID,
+	)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return apierror.ErrorTwoFactorAlreadyEnabled
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	for _, hash := range recoveryCodeHashes {
+		if _, err = tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash); err != nil {
+			_ = tx.Rollback(ctx)
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// UseTotpCounter returns apierror.ErrorInvalidTwoFactorCode if the code of this or later time step was already used.
+func (db *Database) UseTotpCounter(userID, counter int64) error {
+	query := `
+		UPDATE users SET totp_last_counter = $1
+		WHERE id = $2 AND totp_enabled AND (totp_last_counter IS NULL OR totp_last_counter < $1)`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, counter, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// UseRecoveryCode marks the code used, every code works once.
+func (db *Database) UseRecoveryCode(userID int64, codeHash string) error {
+	query := `UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+	tag, err := db.pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return apierror.ErrorInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// DisableTotp removes the secret and recovery codes.
+func (db *Database) DisableTotp(userID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout*2)
+	defer cancel()
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx,
+		`UPDATE users SET totp_secret = NULL, totp_enabled = FALSE, totp_last_counter = NULL WHERE id = $1`,
+		userID,
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,130 @@
+package mailer
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	DefaultMaxAttempts  = 10
+	DefaultPollInterval = 10 * time.Second
+	outboxBatchSize     = 50
+	// outboxLease hides claimed messages from other senders while the batch is being sent.
+	// Messages of the crashed sender are sent again after it.
+	outboxLease = 15 * time.Minute
+)
+
+// Outbox persists messages, so they are not lost if sending fails or the server restarts.
+type Outbox interface {
+	// Store new message.
+	EnqueueEmail(msg *Message) error
+
+	// Claim messages which are due to be sent, they are not returned again until the lease expires.
+	ClaimEmails(limit int, lease time.Duration) ([]*Message, error)
+
+	// Mark message as sent.
+	MarkEmailSent(id int64) error
+
+	// Record failed attempt and schedule the next one, zero nextAttemptAt means giving up.
+	MarkEmailFailed(id int64, sendErr error, nextAttemptAt time.Time) error
+}
+
+type Mailer struct {
+	transport    Transport
+	outbox       Outbox
+	templates    *Templates
+	logger       *zerolog.Logger
+	maxAttempts  int
+	pollInterval time.Duration
+	wakeup       chan struct{}
+}
+
+func New(transport Transport, outbox Outbox, templates *Templates, logger *zerolog.Logger, maxAttempts int, pollInterval time.Duration) *Mailer {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+	return &Mailer{
+		transport:    transport,
+		outbox:       outbox,
+		templates:    templates,
+		logger:       logger,
+		maxAttempts:  maxAttempts,
+		pollInterval: pollInterval,
+		wakeup:       make(chan struct{}, 1),
+	}
+}
+
+// Enqueue renders the template and stores the message in outbox, it will be sent by Run.
+func (m *Mailer) Enqueue(to, locale, template string, data any) error {
+	msg, err := m.templates.Render(locale, template, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	if err = m.outbox.EnqueueEmail(msg); err != nil {
+		return err
+	}
+	select {
+	case m.wakeup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run sends pending messages from outbox until ctx is done.
+func (m *Mailer) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		m.processOutbox()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wakeup:
+		}
+	}
+}
+
+func (m *Mailer) processOutbox() {
+	messages, err := m.outbox.ClaimEmails(outboxBatchSize, outboxLease)
+	if err != nil {
+		m.logger.Error().Err(err).Send()
+		return
+	}
+	for _, msg := range messages {
+		sendErr := m.transport.Send(msg)
+		if sendErr == nil {
+			if err = m.outbox.MarkEmailSent(msg.ID); err != nil {
+				m.logger.Error().Err(err).Send()
+			}
+			continue
+		}
+		attempts := msg.Attempts + 1
+		var nextAttemptAt time.Time
+		if attempts < m.maxAttempts {
+			nextAttemptAt = time.Now().Add(backoff(attempts))
+		}
+		m.logger.Warn().Err(sendErr).Int64("email_id", msg.ID).Int("attempts", attempts).Msg("could not send email")
+		if err = m.outbox.MarkEmailFailed(msg.ID, sendErr, nextAttemptAt); err != nil {
+			m.logger.Error().Err(err).Send()
+		}
+	}
+}
+
+// backoff doubles the delay after each failed attempt, up to 6 hours.
+func backoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 1; i < attempts && delay < 6*time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > 6*time.Hour {
+		delay = 6 * time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,182 @@
+package mailer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryOutbox struct {
+	messages      map[int64]*Message
+	sent          map[int64]bool
+	nextAttemptAt map[int64]time.Time
+	lastID        int64
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{
+		messages:      make(map[int64]*Message),
+		sent:          make(map[int64]bool),
+		nextAttemptAt: make(map[int64]time.Time),
+	}
+}
+
+func (o *memoryOutbox) EnqueueEmail(msg *Message) error {
+	o.lastID++
+	msg.ID = o.lastID
+	o.messages[msg.ID] = msg
+	o.nextAttemptAt[msg.ID] = time.Now()
+	return nil
+}
+
+func (o *memoryOutbox) ClaimEmails(limit int, lease time.Duration) ([]*Message, error) {
+	var messages []*Message
+	for id, msg := range o.messages {
+		next, ok := o.nextAttemptAt[id]
+		if !o.sent[id] && ok && !next.After(time.Now()) {
+			o.nextAttemptAt[id] = time.Now().Add(lease)
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func (o *memoryOutbox) MarkEmailSent(id int64) error {
+	o.sent[id] = true
+	return nil
+}
+
+func (o *memoryOutbox) MarkEmailFailed(id int64, _ error, nextAttemptAt time.Time) error {
+	o.messages[id].Attempts++
+	if nextAttemptAt.IsZero() {
+		delete(o.nextAttemptAt, id)
+	} else {
+		o.nextAttemptAt[id] = nextAttemptAt
+	}
+	return nil
+}
+
+type flakyTransport struct {
+	failures int
+	sent     []*Message
+}
+
+func (t *flakyTransport) Send(msg *Message) error {
+	if t.failures > 0 {
+		t.failures--
+		return errors.New("connection refused")
+	}
+	t.sent = append(t.sent, msg)
+	return nil
+}
+
+func TestRender(t *testing.T) {
+	templates, err := NewTemplates("en")
+	require.NoError(t, err)
+	data := map[string]any{
+		"Username":  "<b>alice</b>",
+		"URL":       "https://renju24.com/verify?token=abc",
+		"ExpiresIn": "24h",
+	}
+
+	msg, err := templates.Render("ru", TemplateVerifyEmail, data)
+	require.NoError(t, err)
+	require.Equal(t, "Подтвердите email на Renju24", msg.Subject)
+	require.Contains(t, msg.Text, "<b>alice</b>")
+	require.Contains(t, msg.HTML, "&lt;b&gt;alice&lt;/b&gt;")
+
+	// Unknown locale falls back to default one.
+	msg, err = templates.Render("de", TemplateVerifyEmail, data)
+	require.NoError(t, err)
+	require.Equal(t, "Confirm your email on Renju24", msg.Subject)
+
+	_, err = templates.Render("en", "unknown", data)
+	require.ErrorIs(t, err, ErrUnknownTemplate)
+}
+
+func TestRetries(t *testing.T) {
+	templates, err := NewTemplates("en")
+	require.NoError(t, err)
+	logger := zerolog.New(io.Discard)
+	outbox := newMemoryOutbox()
+	transport := &flakyTransport{failures: 1}
+	m := New(transport, outbox, templates, &logger, 2, time.Second)
+
+	require.NoError(t, m.Enqueue("alice@example.com", "en", TemplatePasswordReset, map[string]any{
+		"Username":  "alice",
+		"URL":       "https://renju24.com/reset",
+		"ExpiresIn": "1h",
+	}))
+
+	m.processOutbox()
+	require.Empty(t, transport.sent)
+	require.Equal(t, 1, outbox.messages[1].Attempts)
+	require.True(t, outbox.nextAttemptAt[1].After(time.Now()))
+
+	// Retry is due.
+	outbox.nextAttemptAt[1] = time.Now()
+	m.processOutbox()
+	require.Len(t, transport.sent, 1)
+	require.True(t, outbox.sent[1])
+
+	// Give up after maxAttempts.
+	transport.failures = 2
+	require.NoError(t, m.Enqueue("bob@example.com", "en", TemplatePasswordReset, nil))
+	m.processOutbox()
+	outbox.nextAttemptAt[2] = time.Now()
+	m.processOutbox()
+	_, scheduled := outbox.nextAttemptAt[2]
+	require.False(t, scheduled)
+	require.False(t, outbox.sent[2])
+}
+
+func TestClaimedEmailsAreSentOnce(t *testing.T) {
+	templates, err := NewTemplates("en")
+	require.NoError(t, err)
+	logger := zerolog.New(io.Discard)
+	outbox := newMemoryOutbox()
+	transport := &flakyTransport{}
+	// Two servers share the outbox.
+	first := New(transport, outbox, templates, &logger, 2, time.Second)
+	second := New(transport, outbox, templates, &logger, 2, time.Second)
+
+	require.NoError(t, first.Enqueue("alice@example.com", "en", TemplatePasswordReset, nil))
+	claimed, err := outbox.ClaimEmails(outboxBatchSize, outboxLease)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	// The message claimed by the first server is not sent by the second one.
+	second.processOutbox()
+	require.Empty(t, transport.sent)
+
+	// The lease of the crashed sender expires.
+	outbox.nextAttemptAt[1] = time.Now()
+	second.processOutbox()
+	first.processOutbox()
+	require.Len(t, transport.sent, 1)
+}
+
+func TestMessageBytes(t *testing.T) {
+	msg := &Message{
+		To:      "alice@example.com",
+		Subject: "Сброс пароля",
+		Text:    "text body",
+		HTML:    "<p>html body</p>",
+	}
+	data, err := msg.Bytes("noreply@renju24.com")
+	require.NoError(t, err)
+	s := string(data)
+	require.Contains(t, s, "To: <alice@example.com>\r\n")
+	require.Contains(t, s, "Subject: =?utf-8?q?")
+	require.Contains(t, s, "multipart/alternative")
+	require.True(t, strings.Contains(s, "text/plain") && strings.Contains(s, "text/html"))
+
+	// Recipient can't add headers.
+	msg.To = "alice@example.com\r\nBcc: eve@example.com"
+	_, err = msg.Bytes("noreply@renju24.com")
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Templates used by the server.
+const (
+	TemplateVerifyEmail        = "verify_email"
+	TemplatePasswordReset      = "password_reset"
+	TemplateNotificationDigest = "notification_digest"
+	TemplateEmailChanged       = "email_changed"
+)
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Templates renders messages from templates/<locale>/<name>.{subject.txt,txt,html} files.
+type Templates struct {
+	defaultLocale string
+	subjects      map[string]*texttemplate.Template
+	texts         map[string]*texttemplate.Template
+	htmls         map[string]*htmltemplate.Template
+}
+
+// NewTemplates parses embedded templates. Templates of defaultLocale are used if locale has no template.
+func NewTemplates(defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		subjects:      make(map[string]*texttemplate.Template),
+		texts:         make(map[string]*texttemplate.Template),
+		htmls:         make(map[string]*htmltemplate.Template),
+	}
+	err := fs.WalkDir(templatesFS, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := templatesFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		// templates/<locale>/<file>
+		key := strings.TrimPrefix(path, "templates/")
+		switch {
+		case strings.HasSuffix(key, ".subject.txt"):
+			key = strings.TrimSuffix(key, ".subject.txt")
+			t.subjects[key], err = texttemplate.New(key).Parse(string(content))
+		case strings.HasSuffix(key, ".txt"):
+			key = strings.TrimSuffix(key, ".txt")
+			t.texts[key], err = texttemplate.New(key).Parse(string(content))
+		case strings.HasSuffix(key, ".html"):
+			key = strings.TrimSuffix(key, ".html")
+			t.htmls[key], err = htmltemplate.New(key).Parse(string(content))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render returns the message without recipient.
+func (t *Templates) Render(locale, name string, data any) (*Message, error) {
+	key := locale + "/" + name
+	if _, ok := t.subjects[key]; !ok {
+		key = t.defaultLocale + "/" + name
+	}
+	subjectTemplate, ok := t.subjects[key]
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	var msg Message
+	var buf bytes.Buffer
+	if err := subjectTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	msg.Subject = strings.TrimSpace(buf.String())
+	if textTemplate, ok := t.texts[key]; ok {
+		buf.Reset()
+		if err := textTemplate.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		msg.Text = buf.String()
+	}
+	if htmlTemplate, ok := t.htmls[key]; ok {
+		buf.Reset()
+		if err := htmlTemplate.Execute(&buf, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = buf.String()
+	}
+	return &msg, nil
+}
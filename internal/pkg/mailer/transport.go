@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Transport delivers rendered messages.
+type Transport interface {
+	Send(msg *Message) error
+}
+
+// SMTPTransport sends messages through SMTP server, STARTTLS is used if server supports it.
+type SMTPTransport struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (t *SMTPTransport) Send(msg *Message) error {
+	data, err := msg.Bytes(t.From)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+	addr := net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	return smtp.SendMail(addr, auth, t.From, []string{msg.To}, data)
+}
+
+// FileTransport writes messages as .eml files into the directory, it's useful for development.
+type FileTransport struct {
+	Dir  string
+	From string
+}
+
+func (t *FileTransport) Send(msg *Message) error {
+	data, err := msg.Bytes(t.From)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(t.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%d-%d.eml", time.Now().UnixNano(), msg.ID)
+	return os.WriteFile(filepath.Join(t.Dir, name), data, 0o644)
+}
+
+// LogTransport only logs messages, it's useful for development.
+type LogTransport struct {
+	Logger *zerolog.Logger
+}
+
+func (t *LogTransport) Send(msg *Message) error {
+	t.Logger.Info().
+		Str("to", msg.To).
+		Str("subject", msg.Subject).
+		Str("text", msg.Text).
+		Msg("email is not sent because of log transport")
+	return nil
+}
@@ -0,0 +1,58 @@
+package moderation
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrForbiddenWord = errors.New("message contains forbidden word")
+
+// Filter checks chat messages before they are stored and published.
+type Filter interface {
+	Check(text string) error
+}
+
+// WordListFilter rejects messages containing words from any locale's word list.
+// The sender chooses the locale of the message, so it can't be used to skip other lists.
+type WordListFilter struct {
+	words map[string]struct{}
+}
+
+func NewWordListFilter(wordLists map[string][]string) *WordListFilter {
+	f := &WordListFilter{
+		words: make(map[string]struct{}),
+	}
+	for _, words := range wordLists {
+		for _, word := range words {
+			word = normalize(word)
+			if word != "" {
+				f.words[word] = struct{}{}
+			}
+		}
+	}
+	return f
+}
+
+func (f *WordListFilter) Check(text string) error {
+	if len(f.words) == 0 {
+		return nil
+	}
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		if _, ok := f.words[normalize(word)]; ok {
+			return ErrForbiddenWord
+		}
+	}
+	return nil
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// normalize lowercases the word and replaces "ё" with "е",
+// because both spellings are used interchangeably.
+func normalize(word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	return strings.ReplaceAll(word, "ё", "е")
+}
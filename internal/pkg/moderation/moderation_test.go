@@ -0,0 +1,40 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordListFilter(t *testing.T) {
+	filter := NewWordListFilter(map[string][]string{
+		"en": {"Noob"},
+		"ru": {"ёлка"},
+	})
+	testCases := []struct {
+		text          string
+		expectedError error
+	}{
+		{
+			text:          "good game",
+			expectedError: nil,
+		},
+		{
+			text:          "you are a NOOB!",
+			expectedError: ErrForbiddenWord,
+		},
+		{
+			text:          "noobish move",
+			expectedError: nil,
+		},
+		// Words of every locale's list are rejected.
+		{
+			text:          "good game, елка,",
+			expectedError: ErrForbiddenWord,
+		},
+	}
+	for _, testCase := range testCases {
+		actualErr := filter.Check(testCase.text)
+		require.ErrorIs(t, actualErr, testCase.expectedError, testCase.text)
+	}
+}
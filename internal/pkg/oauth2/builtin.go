@@ -0,0 +1,101 @@
+package oauth2
+
+import (
+	"strings"
+
+	"github.com/renju24/backend/internal/pkg/config"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/vk"
+	"golang.org/x/oauth2/yandex"
+)
+
+type builtinProvider struct {
+	provider Provider
+	config   config.OauthProviderConfig
+}
+
+func builtinProviders(cfg config.OauthConfig) []builtinProvider {
+	return []builtinProvider{
+		{
+			provider: Provider{
+				Name:        Google,
+				Logo:        "/assets/images/logos/google.svg",
+				Endpoint:    google.Endpoint,
+				UserInfoURL: "https://www.googleapis.com/oauth2/v2/userinfo?access_token=",
+				Mapping: config.OauthUserInfoConfig{
+					ID:            "id",
+					Email:         "email",
+					EmailVerified: "verified_email",
+				},
+			},
+			config: cfg.Google,
+		},
+		{
+			provider: Provider{
+				Name:        Yandex,
+				Logo:        "/assets/images/logos/yandex.svg",
+				Endpoint:    yandex.Endpoint,
+				UserInfoURL: "https://login.yandex.ru/info?format=json",
+				AuthScheme:  "OAuth",
+				Mapping: config.OauthUserInfoConfig{
+					ID:       "id",
+					Username: "login",
+					Email:    "default_email",
+				},
+			},
+			config: cfg.Yandex,
+		},
+		{
+			provider: Provider{
+				Name:        VK,
+				Logo:        "/assets/images/logos/vk.svg",
+				Endpoint:    vk.Endpoint,
+				UserInfoURL: "https://api.vk.com/method/users.get?fields=screen_name&v=5.131&access_token=",
+				Mapping: config.OauthUserInfoConfig{
+					ID:       "response.0.id",
+					Username: "response.0.screen_name",
+				},
+				EmailFromToken: "email",
+			},
+			config: cfg.VK,
+		},
+		{
+			provider: Provider{
+				Name:        Github,
+				Logo:        "/assets/images/logos/github.svg",
+				Endpoint:    github.Endpoint,
+				UserInfoURL: "https://api.github.com/user",
+				AuthScheme:  "Bearer",
+				Mapping: config.OauthUserInfoConfig{
+					ID:       "id",
+					Username: "login",
+					Email:    "email",
+				},
+				EmailsURL: strings.TrimSuffix(userInfoURL(cfg.Github, "https://api.github.com/user"), "/") + "/emails",
+			},
+			config: cfg.Github,
+		},
+	}
+}
+
+// openIDConnect is the default for custom providers, the user info is mapped from the standard claims.
+func openIDConnect(name Service) Provider {
+	return Provider{
+		Name:       name,
+		AuthScheme: "Bearer",
+		Mapping: config.OauthUserInfoConfig{
+			ID:            "sub",
+			Username:      "preferred_username",
+			Email:         "email",
+			EmailVerified: "email_verified",
+		},
+	}
+}
+
+func userInfoURL(cfg config.OauthProviderConfig, defaultURL string) string {
+	if cfg.API != "" {
+		return cfg.API
+	}
+	return defaultURL
+}
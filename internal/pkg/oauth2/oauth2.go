@@ -1,11 +1,18 @@
 package oauth2
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
 	"github.com/renju24/backend/internal/pkg/config"
 	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-	"golang.org/x/oauth2/vk"
-	"golang.org/x/oauth2/yandex"
+)
+
+var (
+	ErrProviderNotConfigured = errors.New("oauth2 provider is not configured for the platform")
+	ErrInvalidProvider       = errors.New("invalid oauth2 provider config")
 )
 
 type User struct {
@@ -14,50 +21,162 @@ type User struct {
 	Email    *string
 }
 
-func OauthConfig(providers config.OauthConfig, service Service, platform Platform) (*oauth2.Config, error) {
-	switch service {
-	case Google:
-		cfg := &oauth2.Config{
-			ClientID:     providers.Google.ClientID,
-			ClientSecret: providers.Google.ClientSecret,
-			Scopes:       providers.Google.Scopes,
-			Endpoint:     google.Endpoint,
-		}
-		switch platform {
-		case Web:
-			cfg.RedirectURL = providers.Google.Callbacks.Web
-		case Android:
-			cfg.RedirectURL = providers.Google.Callbacks.Android
+// Provider describes how to authorize user in the OAuth2 service and how to read user info.
+type Provider struct {
+	Name Service
+	// Logo is an URL or a path relative to the server.
+	Logo     string
+	Endpoint oauth2.Endpoint
+	// UserInfoURL is requested with the access token to get user's ID, username and email.
+	UserInfoURL string
+	// AuthScheme is the Authorization header scheme, the token is appended to UserInfoURL if it's empty.
+	AuthScheme string
+	Mapping    config.OauthUserInfoConfig
+	// EmailFromToken is the token response field containing email, if user info doesn't have it.
+	EmailFromToken string
+	// EmailsURL lists user's emails if the public one is hidden, the primary verified email is used.
+	EmailsURL string
+
+	clientID     string
+	clientSecret string
+	scopes       []string
+	callbacks    config.OauthRedirects
+}
+
+// Callback returns the redirect URL for the platform, it's empty if the platform is not configured.
+func (p *Provider) Callback(platform Platform) string {
+	switch platform {
+	case Web:
+		return p.callbacks.Web
+	case Android:
+		return p.callbacks.Android
+	case IOS:
+		return p.callbacks.IOS
+	}
+	return ""
+}
+
+func (p *Provider) Config(platform Platform) (*oauth2.Config, error) {
+	callback := p.Callback(platform)
+	if callback == "" {
+		return nil, ErrProviderNotConfigured
+	}
+	return &oauth2.Config{
+		ClientID:     p.clientID,
+		ClientSecret: p.clientSecret,
+		Scopes:       p.scopes,
+		Endpoint:     p.Endpoint,
+		RedirectURL:  callback,
+	}, nil
+}
+
+// User exchanges the code for the access token and returns the user info.
+// Options are passed to the code exchange, e.g. PKCE verifier.
+func (p *Provider) User(ctx context.Context, code string, platform Platform, opts ...oauth2.AuthCodeOption) (*User, error) {
+	cfg, err := p.Config(platform)
+	if err != nil {
+		return nil, err
+	}
+	token, err := cfg.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return p.fetchUser(ctx, token)
+}
+
+var rgxProviderName = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// Registry contains providers configured in config.OauthConfig.
+type Registry struct {
+	providers map[Service]*Provider
+	order     []Service
+}
+
+// NewRegistry registers the built-in providers that have client ID and the custom ones.
+func NewRegistry(cfg config.OauthConfig) (*Registry, error) {
+	r := &Registry{
+		providers: make(map[Service]*Provider),
+	}
+	for _, builtin := range builtinProviders(cfg) {
+		if builtin.config.ClientID == "" {
+			continue
 		}
-		return cfg, nil
-	case Yandex:
-		cfg := &oauth2.Config{
-			ClientID:     providers.Yandex.ClientID,
-			ClientSecret: providers.Yandex.ClientSecret,
-			Scopes:       providers.Yandex.Scopes,
-			Endpoint:     yandex.Endpoint,
+		if err := r.register(newProvider(builtin.provider, builtin.config)); err != nil {
+			return nil, err
 		}
-		switch platform {
-		case Web:
-			cfg.RedirectURL = providers.Yandex.Callbacks.Web
-		case Android:
-			cfg.RedirectURL = providers.Yandex.Callbacks.Android
+	}
+	for _, custom := range cfg.Custom {
+		if !rgxProviderName.MatchString(custom.Name) {
+			return nil, fmt.Errorf("%w: invalid name %q", ErrInvalidProvider, custom.Name)
 		}
-		return cfg, nil
-	case VK:
-		cfg := &oauth2.Config{
-			ClientID:     providers.VK.ClientID,
-			ClientSecret: providers.VK.ClientSecret,
-			Scopes:       providers.VK.Scopes,
-			Endpoint:     vk.Endpoint,
+		if custom.AuthURL == "" || custom.TokenURL == "" || custom.API == "" {
+			return nil, fmt.Errorf("%w: %q must have auth_url, token_url and api_url", ErrInvalidProvider, custom.Name)
 		}
-		switch platform {
-		case Web:
-			cfg.RedirectURL = providers.VK.Callbacks.Web
-		case Android:
-			cfg.RedirectURL = providers.VK.Callbacks.Android
+		if err := r.register(newProvider(openIDConnect(Service(custom.Name)), custom)); err != nil {
+			return nil, err
 		}
-		return cfg, nil
 	}
-	return nil, ErrUnknownService
+	return r, nil
+}
+
+func (r *Registry) register(p *Provider) error {
+	if _, ok := r.providers[p.Name]; ok {
+		return fmt.Errorf("%w: duplicate name %q", ErrInvalidProvider, p.Name)
+	}
+	r.providers[p.Name] = p
+	r.order = append(r.order, p.Name)
+	return nil
+}
+
+// Provider returns ErrUnknownService if the provider is not registered.
+func (r *Registry) Provider(name string) (*Provider, error) {
+	p, ok := r.providers[Service(name)]
+	if !ok {
+		return nil, ErrUnknownService
+	}
+	return p, nil
+}
+
+// Providers returns providers in the order they are registered.
+func (r *Registry) Providers() []*Provider {
+	providers := make([]*Provider, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
+
+// newProvider applies config to the provider defaults.
+func newProvider(p Provider, cfg config.OauthProviderConfig) *Provider {
+	p.clientID = cfg.ClientID
+	p.clientSecret = cfg.ClientSecret
+	p.scopes = cfg.Scopes
+	p.callbacks = cfg.Callbacks
+	if cfg.AuthURL != "" {
+		p.Endpoint.AuthURL = cfg.AuthURL
+	}
+	if cfg.TokenURL != "" {
+		p.Endpoint.TokenURL = cfg.TokenURL
+	}
+	if cfg.API != "" {
+		p.UserInfoURL = cfg.API
+	}
+	if cfg.Logo != "" {
+		p.Logo = cfg.Logo
+	}
+	if cfg.UserInfo.ID != "" {
+		p.Mapping.ID = cfg.UserInfo.ID
+	}
+	if cfg.UserInfo.Username != "" {
+		p.Mapping.Username = cfg.UserInfo.Username
+	}
+	// The verification claim belongs to the email claim, so it's replaced together with it.
+	if cfg.UserInfo.Email != "" {
+		p.Mapping.Email = cfg.UserInfo.Email
+		p.Mapping.EmailVerified = cfg.UserInfo.EmailVerified
+	}
+	if cfg.UserInfo.EmailVerified != "" {
+		p.Mapping.EmailVerified = cfg.UserInfo.EmailVerified
+	}
+	return &p
 }
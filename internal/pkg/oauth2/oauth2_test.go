@@ -0,0 +1,337 @@
+package oauth2
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/renju24/backend/internal/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCode        = "test-code"
+	testAccessToken = "test-access-token"
+)
+
+// fakeGithub is a local OAuth2 server with GitHub-like user API.
+func fakeGithub(t *testing.T, publicEmail *string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		// Handlers run in the server's goroutines, so the mismatch fails the token exchange instead of the test.
+		if r.ParseForm() != nil || r.Form.Get("code") != testCode ||
+			r.Form.Get("redirect_uri") != "https://renju24.com/api/v1/oauth2/ios/github/callback" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": testAccessToken,
+			"token_type":   "bearer",
+		})
+	})
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"id":    583231,
+				"login": "octocat",
+				"email": publicEmail,
+			})
+		}
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			_ = json.NewEncoder(w).Encode([]map[string]any{
+				{"email": "old@example.com", "primary": false, "verified": true},
+				{"email": "octocat@example.com", "primary": true, "verified": true},
+			})
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testProviders(serverURL string) config.OauthConfig {
+	return config.OauthConfig{
+		Github: config.OauthProviderConfig{
+			ClientID:     "client-id",
+			ClientSecret: "client-secret",
+			Callbacks: config.OauthRedirects{
+				Web: "https://renju24.com/api/v1/oauth2/web/github/callback",
+				IOS: "https://renju24.com/api/v1/oauth2/ios/github/callback",
+			},
+			API:      serverURL + "/user",
+			AuthURL:  serverURL + "/login/oauth/authorize",
+			TokenURL: serverURL + "/login/oauth/access_token",
+		},
+	}
+}
+
+func testRegistry(t *testing.T, cfg config.OauthConfig) *Registry {
+	registry, err := NewRegistry(cfg)
+	require.NoError(t, err)
+	return registry
+}
+
+func testProvider(t *testing.T, cfg config.OauthConfig, name Service) *Provider {
+	provider, err := testRegistry(t, cfg).Provider(string(name))
+	require.NoError(t, err)
+	return provider
+}
+
+func TestGithubOauth(t *testing.T) {
+	publicEmail := "public@example.com"
+	server := fakeGithub(t, &publicEmail)
+	user, err := testProvider(t, testProviders(server.URL), Github).User(context.Background(), testCode, IOS)
+	require.NoError(t, err)
+	require.Equal(t, "583231", user.ID)
+	require.Equal(t, "octocat", user.Username)
+	require.NotNil(t, user.Email)
+	require.Equal(t, publicEmail, *user.Email)
+}
+
+func TestGithubOauthHiddenEmail(t *testing.T) {
+	server := fakeGithub(t, nil)
+	user, err := testProvider(t, testProviders(server.URL), Github).User(context.Background(), testCode, IOS)
+	require.NoError(t, err)
+	require.NotNil(t, user.Email)
+	require.Equal(t, "octocat@example.com", *user.Email)
+}
+
+func TestGithubOauthAPIError(t *testing.T) {
+	server := fakeGithub(t, nil)
+	providers := testProviders(server.URL)
+	providers.Github.API = server.URL + "/missing"
+	_, err := testProvider(t, providers, Github).User(context.Background(), testCode, IOS)
+	require.Error(t, err)
+}
+
+// fakeOpenID is a local OpenID Connect server with VK-like token response.
+func fakeOpenID(t *testing.T, userInfo any) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": testAccessToken,
+			"token_type":   "bearer",
+			"email":        "token@example.com",
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(userInfo)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func customProvider(serverURL string) config.OauthProviderConfig {
+	return config.OauthProviderConfig{
+		Name:         "keycloak",
+		Logo:         "https://sso.example.com/logo.svg",
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Callbacks: config.OauthRedirects{
+			Web: "https://renju24.com/api/v1/oauth2/web/keycloak/callback",
+		},
+		AuthURL:  serverURL + "/auth",
+		TokenURL: serverURL + "/token",
+		API:      serverURL + "/userinfo",
+	}
+}
+
+func TestCustomOpenID(t *testing.T) {
+	server := fakeOpenID(t, map[string]any{
+		"sub":                "f47ac10b",
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+	})
+	cfg := config.OauthConfig{Custom: []config.OauthProviderConfig{customProvider(server.URL)}}
+	provider := testProvider(t, cfg, "keycloak")
+	require.Equal(t, "https://sso.example.com/logo.svg", provider.Logo)
+
+	user, err := provider.User(context.Background(), testCode, Web)
+	require.NoError(t, err)
+	require.Equal(t, "f47ac10b", user.ID)
+	require.Equal(t, "alice", user.Username)
+	require.Equal(t, "alice@example.com", *user.Email)
+
+	_, err = provider.User(context.Background(), testCode, Android)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+}
+
+func TestCustomUserInfoMapping(t *testing.T) {
+	server := fakeOpenID(t, map[string]any{
+		"response": []map[string]any{
+			{"id": 9007199254740993, "first_name": "Bob"},
+		},
+	})
+	custom := customProvider(server.URL)
+	custom.UserInfo = config.OauthUserInfoConfig{
+		ID:       "response.0.id",
+		Username: "response.0.screen_name",
+		Email:    "response.0.email",
+	}
+	user, err := testProvider(t, config.OauthConfig{Custom: []config.OauthProviderConfig{custom}}, "keycloak").User(context.Background(), testCode, Web)
+	require.NoError(t, err)
+	// Large numeric IDs are not rounded.
+	require.Equal(t, "9007199254740993", user.ID)
+	require.Equal(t, "keycloak9007199254740993", user.Username)
+	require.Nil(t, user.Email)
+}
+
+func TestEmailVerified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		claims   map[string]any
+		mapping  config.OauthUserInfoConfig
+		verified bool
+	}{
+		{
+			name:     "verified",
+			claims:   map[string]any{"email_verified": true},
+			verified: true,
+		},
+		{
+			name:     "verified string",
+			claims:   map[string]any{"email_verified": "true"},
+			verified: true,
+		},
+		{
+			name:   "unverified",
+			claims: map[string]any{"email_verified": false},
+		},
+		{
+			name:   "no claim",
+			claims: map[string]any{},
+		},
+		{
+			name:     "custom claim",
+			claims:   map[string]any{"mail": "alice@example.com", "mail_confirmed": true},
+			mapping:  config.OauthUserInfoConfig{Email: "mail", EmailVerified: "mail_confirmed"},
+			verified: true,
+		},
+		{
+			// The custom email claim has no verification claim, so the provider is trusted.
+			name:     "custom email",
+			claims:   map[string]any{"mail": "alice@example.com"},
+			mapping:  config.OauthUserInfoConfig{Email: "mail"},
+			verified: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := map[string]any{"sub": "f47ac10b", "email": "alice@example.com"}
+			for key, value := range tc.claims {
+				claims[key] = value
+			}
+			custom := customProvider(fakeOpenID(t, claims).URL)
+			custom.UserInfo = tc.mapping
+			user, err := testProvider(t, config.OauthConfig{Custom: []config.OauthProviderConfig{custom}}, "keycloak").User(context.Background(), testCode, Web)
+			require.NoError(t, err)
+			if !tc.verified {
+				require.Nil(t, user.Email)
+				return
+			}
+			require.NotNil(t, user.Email)
+			require.Equal(t, "alice@example.com", *user.Email)
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	cfg := testProviders("http://127.0.0.1")
+	cfg.Google.ClientID = "google-client-id"
+	cfg.Custom = []config.OauthProviderConfig{customProvider("http://127.0.0.1")}
+	registry := testRegistry(t, cfg)
+
+	// Providers without client ID are not registered.
+	var names []Service
+	for _, provider := range registry.Providers() {
+		names = append(names, provider.Name)
+	}
+	require.Equal(t, []Service{Google, Github, "keycloak"}, names)
+	_, err := registry.Provider("yandex")
+	require.ErrorIs(t, err, ErrUnknownService)
+	_, err = registry.Provider("facebook")
+	require.ErrorIs(t, err, ErrUnknownService)
+
+	google, err := registry.Provider("google")
+	require.NoError(t, err)
+	require.Equal(t, "/assets/images/logos/google.svg", google.Logo)
+
+	duplicate := customProvider("http://127.0.0.1")
+	duplicate.Name = "github"
+	_, err = NewRegistry(config.OauthConfig{Github: cfg.Github, Custom: []config.OauthProviderConfig{duplicate}})
+	require.ErrorIs(t, err, ErrInvalidProvider)
+
+	incomplete := customProvider("http://127.0.0.1")
+	incomplete.TokenURL = ""
+	_, err = NewRegistry(config.OauthConfig{Custom: []config.OauthProviderConfig{incomplete}})
+	require.ErrorIs(t, err, ErrInvalidProvider)
+}
+
+func TestProviderConfig(t *testing.T) {
+	providers := testProviders("http://127.0.0.1")
+	provider := testProvider(t, providers, Github)
+	cfg, err := provider.Config(IOS)
+	require.NoError(t, err)
+	require.Equal(t, providers.Github.Callbacks.IOS, cfg.RedirectURL)
+	require.Equal(t, providers.Github.TokenURL, cfg.Endpoint.TokenURL)
+
+	authURL, err := url.Parse(cfg.AuthCodeURL("state"))
+	require.NoError(t, err)
+	require.Equal(t, "/login/oauth/authorize", authURL.Path)
+	require.Equal(t, "state", authURL.Query().Get("state"))
+
+	_, err = provider.Config(Android)
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+
+	providers.Github.AuthURL = ""
+	providers.Github.TokenURL = ""
+	cfg, err = testProvider(t, providers, Github).Config(Web)
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/login/oauth/access_token", cfg.Endpoint.TokenURL)
+}
+
+func TestParse(t *testing.T) {
+	for _, s := range []string{"web", "android", "ios"} {
+		platform, err := ParsePlatform(s)
+		require.NoError(t, err)
+		require.Equal(t, Platform(s), platform)
+	}
+	_, err := ParsePlatform("windows")
+	require.ErrorIs(t, err, ErrUnknownPlatform)
+}
+
+func TestPKCE(t *testing.T) {
+	// Example from RFC 7636, Appendix B.
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", S256Challenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	verifier, err := NewVerifier()
+	require.NoError(t, err)
+	require.Len(t, verifier, 43)
+
+	cfg, err := testProvider(t, testProviders("http://127.0.0.1"), Github).Config(IOS)
+	require.NoError(t, err)
+	authURL, err := url.Parse(cfg.AuthCodeURL("state", ChallengeOptions(S256Challenge(verifier))...))
+	require.NoError(t, err)
+	require.Equal(t, S256Challenge(verifier), authURL.Query().Get("code_challenge"))
+	require.Equal(t, "S256", authURL.Query().Get("code_challenge_method"))
+}
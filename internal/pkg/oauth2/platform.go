@@ -18,6 +18,8 @@ func ParsePlatform(s string) (Platform, error) {
 		return Web, nil
 	case "android":
 		return Android, nil
+	case "ios":
+		return IOS, nil
 	}
 	return "", ErrUnknownPlatform
 }
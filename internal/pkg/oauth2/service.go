@@ -4,22 +4,13 @@ import "errors"
 
 var ErrUnknownService = errors.New("unknown service")
 
+// Service is the provider name, it's stored in database with user's ID in the service.
 type Service string
 
+// Built-in services, custom ones are added by config.
 const (
 	Google Service = "google"
 	Yandex Service = "yandex"
 	VK     Service = "vk"
+	Github Service = "github"
 )
-
-func ParseService(s string) (Service, error) {
-	switch s {
-	case "google":
-		return Google, nil
-	case "yandex":
-		return Yandex, nil
-	case "vk":
-		return VK, nil
-	}
-	return "", ErrUnknownService
-}
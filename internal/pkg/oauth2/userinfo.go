@@ -0,0 +1,142 @@
+package oauth2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+func (p *Provider) fetchUser(ctx context.Context, token *oauth2.Token) (*User, error) {
+	var info any
+	if err := p.get(ctx, p.UserInfoURL, token.AccessToken, &info); err != nil {
+		return nil, err
+	}
+	id := lookup(info, p.Mapping.ID)
+	if id == "" {
+		return nil, fmt.Errorf("%s: user info has no %q", p.Name, p.Mapping.ID)
+	}
+	user := &User{
+		ID:       id,
+		Username: lookup(info, p.Mapping.Username),
+	}
+	email := lookup(info, p.Mapping.Email)
+	// Unverified email could belong to someone else, it would let him take over the account with this email.
+	if p.Mapping.EmailVerified != "" && !lookupBool(info, p.Mapping.EmailVerified) {
+		email = ""
+	}
+	if email == "" && p.EmailFromToken != "" {
+		email, _ = token.Extra(p.EmailFromToken).(string)
+	}
+	if email == "" && p.EmailsURL != "" {
+		email = p.primaryEmail(ctx, token.AccessToken)
+	}
+	if email != "" {
+		user.Email = &email
+	}
+	if user.Username == "" {
+		if at := strings.Index(email, "@"); at > 0 {
+			user.Username = email[:at]
+		} else {
+			user.Username = string(p.Name) + id
+		}
+	}
+	return user, nil
+}
+
+// primaryEmail returns the primary verified email from GitHub-like emails list.
+func (p *Provider) primaryEmail(ctx context.Context, accessToken string) string {
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, p.EmailsURL, accessToken, &emails); err != nil {
+		return ""
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			return email.Email
+		}
+	}
+	return ""
+}
+
+func (p *Provider) get(ctx context.Context, url, accessToken string, v any) error {
+	if p.AuthScheme == "" {
+		url += accessToken
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if p.AuthScheme != "" {
+		req.Header.Set("Authorization", p.AuthScheme+" "+accessToken)
+	}
+	req.Header.Set("Accept", "application/json")
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: user info responded with status %d", p.Name, response.StatusCode)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Numeric IDs must not be converted to float.
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// lookup returns the string or number at the dot-separated path, array elements are addressed by index.
+func lookup(v any, path string) string {
+	switch value := lookupValue(v, path).(type) {
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	}
+	return ""
+}
+
+// lookupBool reports whether the value at the path is true, some providers send it as a string.
+func lookupBool(v any, path string) bool {
+	switch value := lookupValue(v, path).(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	}
+	return false
+}
+
+func lookupValue(v any, path string) any {
+	if path == "" {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
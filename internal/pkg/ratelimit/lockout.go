@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Lockout blocks a key after repeated failures, e.g. wrong passwords for a login.
+// Every failure over the threshold doubles the lock duration up to the maximum.
+// Failures are forgotten after the maximum duration without new ones.
+type Lockout struct {
+	threshold int
+	base      time.Duration
+	max       time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*lockoutEntry
+	cleaned time.Time
+}
+
+type lockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewLockout creates Lockout which locks the key for base duration after threshold failures.
+func NewLockout(threshold int, base, max time.Duration) *Lockout {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if base <= 0 {
+		base = time.Second
+	}
+	if max < base {
+		max = base
+	}
+	return &Lockout{
+		threshold: threshold,
+		base:      base,
+		max:       max,
+		now:       time.Now,
+		entries:   make(map[string]*lockoutEntry),
+	}
+}
+
+// Check returns how long the key is still locked, zero if it's not.
+func (l *Lockout) Check(key string) (retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		return 0
+	}
+	if now := l.now(); now.Before(e.lockedUntil) {
+		return e.lockedUntil.Sub(now)
+	}
+	return 0
+}
+
+// Fail records the failure and returns the lock duration, zero if the threshold is not reached yet.
+func (l *Lockout) Fail(key string) (lockedFor time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.cleanup(now)
+	e, ok := l.entries[key]
+	if !ok || now.Sub(e.lastFailure) >= l.max {
+		e = &lockoutEntry{}
+		l.entries[key] = e
+	}
+	e.failures++
+	e.lastFailure = now
+	if e.failures < l.threshold {
+		return 0
+	}
+	lockedFor = l.base
+	for i := l.threshold; i < e.failures && lockedFor < l.max; i++ {
+		lockedFor *= 2
+	}
+	if lockedFor > l.max {
+		lockedFor = l.max
+	}
+	e.lockedUntil = now.Add(lockedFor)
+	return lockedFor
+}
+
+// Success forgets the key's failures.
+func (l *Lockout) Success(key string) {
+	l.mu.Lock()
+	delete(l.entries, key)
+	l.mu.Unlock()
+}
+
+func (l *Lockout) cleanup(now time.Time) {
+	if now.Sub(l.cleaned) < l.max {
+		return
+	}
+	for key, e := range l.entries {
+		if now.Sub(e.lastFailure) >= l.max && !now.Before(e.lockedUntil) {
+			delete(l.entries, key)
+		}
+	}
+	l.cleaned = now
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockout(t *testing.T) {
+	const (
+		fail = iota
+		check
+		success
+	)
+	type step struct {
+		// advance moves the clock before the action.
+		advance time.Duration
+		action  int
+		key     string
+		// expected is the lock duration returned by Fail or Check.
+		expected time.Duration
+	}
+	// Threshold is 3 failures, first lock is 30s and the longest is 2m.
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "below threshold",
+			steps: []step{
+				{action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: check, key: "a"},
+			},
+		},
+		{
+			name: "lock doubles up to max",
+			steps: []step{
+				{action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: fail, key: "a", expected: 30 * time.Second},
+				{action: fail, key: "a", expected: time.Minute},
+				{action: fail, key: "a", expected: 2 * time.Minute},
+				{action: fail, key: "a", expected: 2 * time.Minute},
+				{action: check, key: "a", expected: 2 * time.Minute},
+				{advance: time.Minute, action: check, key: "a", expected: time.Minute},
+			},
+		},
+		{
+			name: "separate keys",
+			steps: []step{
+				{action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: fail, key: "a", expected: 30 * time.Second},
+				{action: check, key: "b"},
+				{action: fail, key: "b"},
+			},
+		},
+		{
+			name: "lock expires and failures are forgotten",
+			steps: []step{
+				{action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: fail, key: "a", expected: 30 * time.Second},
+				{advance: 30 * time.Second, action: check, key: "a"},
+				{advance: 90 * time.Second, action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: fail, key: "a", expected: 30 * time.Second},
+			},
+		},
+		{
+			name: "success unlocks",
+			steps: []step{
+				{action: fail, key: "a"},
+				{action: fail, key: "a"},
+				{action: fail, key: "a", expected: 30 * time.Second},
+				{action: success, key: "a"},
+				{action: check, key: "a"},
+				{action: fail, key: "a"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			l := NewLockout(3, 30*time.Second, 2*time.Minute)
+			l.now = func() time.Time { return now }
+			for i, s := range tc.steps {
+				now = now.Add(s.advance)
+				switch s.action {
+				case fail:
+					require.Equal(t, s.expected, l.Fail(s.key), "step %d", i)
+				case check:
+					require.Equal(t, s.expected, l.Check(s.key), "step %d", i)
+				case success:
+					l.Success(s.key)
+				}
+			}
+		})
+	}
+}
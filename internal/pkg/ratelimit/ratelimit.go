@@ -0,0 +1,89 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is a set of token buckets identified by a key, e.g. an IP address or an email.
+// Each bucket holds up to burst tokens and is refilled with rate tokens per second.
+type Limiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	cleaned time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// New creates Limiter allowing burst requests at once and requests per period on average.
+func New(requests int, period time.Duration) *Limiter {
+	if requests <= 0 {
+		requests = 1
+	}
+	if period <= 0 {
+		period = time.Second
+	}
+	return &Limiter{
+		rate:    float64(requests) / period.Seconds(),
+		burst:   float64(requests),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the key's bucket and reports whether it was available.
+func (l *Limiter) Allow(key string) bool {
+	ok, _ := l.Reserve(key)
+	return ok
+}
+
+// Reserve is like Allow, but also returns how long to wait until the next token if there is none.
+func (l *Limiter) Reserve(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.cleanup(now)
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.updated).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.updated = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Reset removes the key's bucket, e.g. after successful login.
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	delete(l.buckets, key)
+	l.mu.Unlock()
+}
+
+// cleanup removes full buckets, they are indistinguishable from the new ones.
+func (l *Limiter) cleanup(now time.Time) {
+	fillTime := time.Duration(l.burst / l.rate * float64(time.Second))
+	if now.Sub(l.cleaned) < fillTime {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) >= fillTime {
+			delete(l.buckets, key)
+		}
+	}
+	l.cleaned = now
+}
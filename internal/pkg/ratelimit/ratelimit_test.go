@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	type step struct {
+		// advance moves the clock before the request.
+		advance    time.Duration
+		key        string
+		reset      bool
+		ok         bool
+		retryAfter time.Duration
+	}
+	testCases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "burst",
+			steps: []step{
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", retryAfter: 20 * time.Second},
+				{key: "a", retryAfter: 20 * time.Second},
+			},
+		},
+		{
+			name: "separate keys",
+			steps: []step{
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "b", ok: true},
+			},
+		},
+		{
+			name: "refill",
+			steps: []step{
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{advance: 10 * time.Second, key: "a", retryAfter: 10 * time.Second},
+				{advance: 10 * time.Second, key: "a", ok: true},
+				// Only one token is refilled.
+				{key: "a", retryAfter: 20 * time.Second},
+			},
+		},
+		{
+			name: "reset",
+			steps: []step{
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", reset: true, ok: true},
+				{key: "a", ok: true},
+				{key: "a", ok: true},
+				{key: "a", retryAfter: 20 * time.Second},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Unix(1000, 0)
+			l := New(3, time.Minute)
+			l.now = func() time.Time { return now }
+			for i, s := range tc.steps {
+				now = now.Add(s.advance)
+				if s.reset {
+					l.Reset(s.key)
+				}
+				ok, retryAfter := l.Reserve(s.key)
+				require.Equal(t, s.ok, ok, "step %d", i)
+				require.Equal(t, s.retryAfter, retryAfter, "step %d", i)
+			}
+		})
+	}
+}
+
+func TestLimiterCleanup(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := New(2, time.Second)
+	l.now = func() time.Time { return now }
+	require.True(t, l.Allow("a"))
+	require.True(t, l.Allow("b"))
+	now = now.Add(2 * time.Second)
+	require.True(t, l.Allow("c"))
+	// Idle buckets are full, so they are removed.
+	require.Len(t, l.buckets, 1)
+	require.Contains(t, l.buckets, "c")
+}
@@ -0,0 +1,32 @@
+package securetoken
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner(t *testing.T) {
+	signer := NewSigner("secret")
+	token, hash, err := signer.New("verify_email")
+	require.NoError(t, err)
+	require.Equal(t, Hash(token), hash)
+
+	actualHash, err := signer.Verify("verify_email", token)
+	require.NoError(t, err)
+	require.Equal(t, hash, actualHash)
+
+	// Token issued for another purpose.
+	_, err = signer.Verify("password_reset", token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Token signed with another key.
+	_, err = NewSigner("another secret").Verify("verify_email", token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Malformed tokens.
+	for _, malformed := range []string{"", "abc", token + "x", "." + token} {
+		_, err = signer.Verify("verify_email", malformed)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
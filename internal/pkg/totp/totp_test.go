@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHOTP(t *testing.T) {
+	// Test vectors from RFC 6238, Appendix B (SHA1).
+	key := []byte("12345678901234567890")
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		require.Equal(t, tc.code, hotp(key, Counter(time.Unix(tc.unix, 0)), 8))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	code, err := Code(secret, Counter(now))
+	require.NoError(t, err)
+	require.Equal(t, "081804", code)
+
+	counter, ok := Verify(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, Counter(now), counter)
+
+	// Previous and next steps are accepted because of clock drift.
+	counter, ok = Verify(secret, code, now.Add(Period*time.Second))
+	require.True(t, ok)
+	require.Equal(t, Counter(now), counter)
+	_, ok = Verify(secret, code, now.Add(-Period*time.Second))
+	require.True(t, ok)
+
+	_, ok = Verify(secret, code, now.Add(2*Period*time.Second))
+	require.False(t, ok)
+	_, ok = Verify(secret, "000000", now)
+	require.False(t, ok)
+	_, ok = Verify(secret, "81804", now)
+	require.False(t, ok)
+	_, ok = Verify("not base32!", code, now)
+	require.False(t, ok)
+}
+
+func TestNewSecret(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+	other, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+
+	uri, err := url.Parse(URI("Renju24", "alice", secret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", uri.Scheme)
+	require.Equal(t, "totp", uri.Host)
+	require.Equal(t, "/Renju24:alice", uri.Path)
+	require.Equal(t, secret, uri.Query().Get("secret"))
+	require.Equal(t, "Renju24", uri.Query().Get("issuer"))
+	require.Equal(t, "6", uri.Query().Get("digits"))
+}
@@ -0,0 +1,74 @@
+package webpush
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const vapidTokenLifetime = 12 * time.Hour
+
+// GenerateVAPIDKeys returns new base64url encoded VAPID key pair.
+func GenerateVAPIDKeys() (privateKey, publicKey string, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	d := make([]byte, 32)
+	key.D.FillBytes(d)
+	return encodeBase64(d), encodeBase64(elliptic.Marshal(key.Curve, key.X, key.Y)), nil
+}
+
+// ParseVAPIDPrivateKey parses base64url encoded P-256 private scalar.
+func ParseVAPIDPrivateKey(s string) (*ecdsa.PrivateKey, error) {
+	d, err := decodeBase64(s)
+	if err != nil || len(d) != 32 {
+		return nil, ErrInvalidVAPIDKey
+	}
+	curve := elliptic.P256()
+	key := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(d)}
+	if key.D.Sign() == 0 || key.D.Cmp(curve.Params().N) >= 0 {
+		return nil, ErrInvalidVAPIDKey
+	}
+	key.Curve = curve
+	key.X, key.Y = curve.ScalarBaseMult(d)
+	return key, nil
+}
+
+// vapidToken returns ES256 signed JWT for the push service origin.
+func (s *Sender) vapidToken(audience string) (string, error) {
+	header := encodeBase64([]byte(`{"typ":"JWT","alg":"ES256"}`))
+	claims, err := json.Marshal(map[string]any{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenLifetime).Unix(),
+		"sub": s.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+	signingInput := header + "." + encodeBase64(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.privateKey, digest[:])
+	if err != nil {
+		return "", err
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	return signingInput + "." + encodeBase64(signature), nil
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeBase64 accepts both padded and unpadded base64url, browsers are not consistent here.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,239 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+var (
+	ErrInvalidSubscription = errors.New("invalid push subscription")
+	ErrSubscriptionGone    = errors.New("push subscription is expired or unsubscribed")
+	ErrPayloadTooLarge     = errors.New("push payload is too large")
+	ErrInvalidVAPIDKey     = errors.New("invalid VAPID private key")
+)
+
+const (
+	recordSize = 4096
+	// The header consists of salt, record size, key id length and sender's public key.
+	headerSize = 16 + 4 + 1 + 65
+	// Plaintext is followed by the delimiter byte and AEAD tag within the single record.
+	MaxPayloadSize = recordSize - headerSize - 1 - 16
+)
+
+// Subscription is the PushSubscription received from browser.
+// Keys are base64url encoded as in PushSubscription.toJSON().
+type Subscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+func (s *Subscription) decodeKeys() (userAgentPublic, authSecret []byte, err error) {
+	userAgentPublic, err = decodeBase64(s.Keys.P256dh)
+	if err != nil || len(userAgentPublic) != 65 || userAgentPublic[0] != 4 {
+		return nil, nil, ErrInvalidSubscription
+	}
+	authSecret, err = decodeBase64(s.Keys.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, nil, ErrInvalidSubscription
+	}
+	return userAgentPublic, authSecret, nil
+}
+
+// PushServiceHosts are the push services of the browsers, subdomains are allowed too.
+// The server posts to the subscription endpoint, so any other host would let users make it
+// send requests into the private network.
+var PushServiceHosts = []string{
+	"fcm.googleapis.com",        // Chrome, Opera, Edge on Android.
+	"push.services.mozilla.com", // Firefox.
+	"notify.windows.com",        // Edge.
+	"push.apple.com",            // Safari.
+}
+
+// Validate checks the endpoint URL and the subscription keys.
+// Insecure mode is for development, it allows http and any host, e.g. a local fake push service.
+func (s *Subscription) Validate(allowInsecure bool) error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Host == "" || endpoint.User != nil {
+		return ErrInvalidSubscription
+	}
+	if !allowInsecure && (endpoint.Scheme != "https" || endpoint.Port() != "" || !isPushServiceHost(endpoint.Hostname())) {
+		return ErrInvalidSubscription
+	}
+	if allowInsecure && endpoint.Scheme != "https" && endpoint.Scheme != "http" {
+		return ErrInvalidSubscription
+	}
+	_, _, err = s.decodeKeys()
+	return err
+}
+
+func isPushServiceHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, pushHost := range PushServiceHosts {
+		if host == pushHost || strings.HasSuffix(host, "."+pushHost) {
+			return true
+		}
+	}
+	return false
+}
+
+// Encrypt encrypts the payload using "aes128gcm" content encoding as described in RFC 8291.
+func Encrypt(sub *Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+	userAgentPublic, authSecret, err := sub.decodeKeys()
+	if err != nil {
+		return nil, err
+	}
+	curve := elliptic.P256()
+	uaX, uaY := elliptic.Unmarshal(curve, userAgentPublic)
+	if uaX == nil {
+		return nil, ErrInvalidSubscription
+	}
+	// Ephemeral application server key pair.
+	asPrivate, asX, asY, err := elliptic.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := elliptic.Marshal(curve, asX, asY)
+	sharedX, _ := curve.ScalarMult(uaX, uaY, asPrivate)
+	ecdhSecret := make([]byte, 32)
+	sharedX.FillBytes(ecdhSecret)
+
+	salt := make([]byte, 16)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), userAgentPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, authSecret), keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 delimits the last (and the only) record.
+	plaintext := append(append([]byte{}, payload...), 2)
+
+	var body bytes.Buffer
+	body.Write(salt)
+	_ = binary.Write(&body, binary.BigEndian, uint32(recordSize))
+	body.WriteByte(byte(len(asPublic)))
+	body.Write(asPublic)
+	body.Write(gcm.Seal(nil, nonce, plaintext, nil))
+	return body.Bytes(), nil
+}
+
+func expand(prk, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, info), out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Sender sends encrypted push messages authenticated with VAPID (RFC 8292).
+type Sender struct {
+	client     *http.Client
+	privateKey *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	ttl        time.Duration
+}
+
+// NewSender creates Sender from base64url encoded VAPID private key.
+// Subject is a "mailto:" or "https:" contact URI of the application server.
+func NewSender(vapidPrivateKey, subject string, ttl time.Duration, client *http.Client) (*Sender, error) {
+	privateKey, err := ParseVAPIDPrivateKey(vapidPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{
+		client:     client,
+		privateKey: privateKey,
+		publicKey:  encodeBase64(elliptic.Marshal(privateKey.Curve, privateKey.X, privateKey.Y)),
+		subject:    subject,
+		ttl:        ttl,
+	}, nil
+}
+
+// PublicKey returns base64url encoded VAPID public key that browsers need as applicationServerKey.
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
+
+// Send returns ErrSubscriptionGone if the subscription should be removed.
+func (s *Sender) Send(sub *Subscription, payload []byte) error {
+	body, err := Encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil {
+		return ErrInvalidSubscription
+	}
+	token, err := s.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(s.ttl.Seconds())))
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.publicKey)
+	response, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	switch {
+	case response.StatusCode == http.StatusNotFound || response.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case response.StatusCode >= 300:
+		return fmt.Errorf("push service responded with status %d", response.StatusCode)
+	}
+	return nil
+}
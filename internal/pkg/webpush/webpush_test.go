@@ -0,0 +1,191 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/hkdf"
+)
+
+// userAgent is the browser side of a push subscription.
+type userAgent struct {
+	privateKey []byte
+	publicKey  []byte
+	authSecret []byte
+}
+
+func newUserAgent(t *testing.T) *userAgent {
+	privateKey, x, y, err := elliptic.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	authSecret := make([]byte, 16)
+	_, err = rand.Read(authSecret)
+	require.NoError(t, err)
+	return &userAgent{
+		privateKey: privateKey,
+		publicKey:  elliptic.Marshal(elliptic.P256(), x, y),
+		authSecret: authSecret,
+	}
+}
+
+func (ua *userAgent) subscription(endpoint string) *Subscription {
+	sub := &Subscription{Endpoint: endpoint}
+	sub.Keys.P256dh = encodeBase64(ua.publicKey)
+	sub.Keys.Auth = encodeBase64(ua.authSecret)
+	return sub
+}
+
+// decrypt is the receiver side of RFC 8291.
+func (ua *userAgent) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), headerSize)
+	salt := body[:16]
+	require.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	require.Equal(t, byte(65), body[20])
+	asPublic := body[21:86]
+
+	curve := elliptic.P256()
+	asX, asY := elliptic.Unmarshal(curve, asPublic)
+	require.NotNil(t, asX)
+	sharedX, _ := curve.ScalarMult(asX, asY, ua.privateKey)
+	ecdhSecret := make([]byte, 32)
+	sharedX.FillBytes(ecdhSecret)
+
+	keyInfo := append([]byte("WebPush: info\x00"), ua.publicKey...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := expand(hkdf.Extract(sha256.New, ecdhSecret, ua.authSecret), keyInfo, 32)
+	require.NoError(t, err)
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek, err := expand(prk, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	require.NoError(t, err)
+	nonce, err := expand(prk, []byte("Content-Encoding: nonce\x00"), 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[headerSize:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(2), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func verifyVAPID(t *testing.T, authorization, publicKey, audience string) {
+	require.True(t, strings.HasPrefix(authorization, "vapid "))
+	params := map[string]string{}
+	for _, param := range strings.Split(strings.TrimPrefix(authorization, "vapid "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		require.Len(t, kv, 2)
+		params[kv[0]] = kv[1]
+	}
+	require.Equal(t, publicKey, params["k"])
+
+	parts := strings.Split(params["t"], ".")
+	require.Len(t, parts, 3)
+	claimsJSON, err := decodeBase64(parts[1])
+	require.NoError(t, err)
+	var claims struct {
+		Aud string `json:"aud"`
+		Exp int64  `json:"exp"`
+		Sub string `json:"sub"`
+	}
+	require.NoError(t, json.Unmarshal(claimsJSON, &claims))
+	require.Equal(t, audience, claims.Aud)
+	require.Greater(t, claims.Exp, time.Now().Unix())
+	require.Equal(t, "mailto:admin@renju24.com", claims.Sub)
+
+	keyBytes, err := decodeBase64(params["k"])
+	require.NoError(t, err)
+	x, y := elliptic.Unmarshal(elliptic.P256(), keyBytes)
+	require.NotNil(t, x)
+	signature, err := decodeBase64(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.True(t, ecdsa.Verify(
+		&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y},
+		digest[:],
+		new(big.Int).SetBytes(signature[:32]),
+		new(big.Int).SetBytes(signature[32:]),
+	))
+}
+
+func TestSend(t *testing.T) {
+	privateKey, publicKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	sender, err := NewSender(privateKey, "mailto:admin@renju24.com", time.Hour, nil)
+	require.NoError(t, err)
+	require.Equal(t, publicKey, sender.PublicKey())
+
+	ua := newUserAgent(t)
+	payload := []byte(`{"event_type":"game_invitation","data":{"game_id":1}}`)
+
+	// Fake push service. The request is checked after Send returns, require can't be used in the server's goroutines.
+	var (
+		received http.Header
+		body     []byte
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	sub := ua.subscription(server.URL + "/push/abc")
+	require.NoError(t, sub.Validate(true))
+	require.ErrorIs(t, sub.Validate(false), ErrInvalidSubscription)
+
+	require.NoError(t, sender.Send(sub, payload))
+	require.NotNil(t, received)
+	require.Equal(t, "aes128gcm", received.Get("Content-Encoding"))
+	require.Equal(t, "3600", received.Get("TTL"))
+	verifyVAPID(t, received.Get("Authorization"), publicKey, server.URL)
+	require.Equal(t, payload, ua.decrypt(t, body))
+
+	require.ErrorIs(t, sender.Send(ua.subscription(server.URL+"/gone"), payload), ErrSubscriptionGone)
+	require.ErrorIs(t, sender.Send(sub, make([]byte, MaxPayloadSize+1)), ErrPayloadTooLarge)
+}
+
+func TestInvalidSubscription(t *testing.T) {
+	ua := newUserAgent(t)
+	testCases := []func(sub *Subscription){
+		func(sub *Subscription) { sub.Endpoint = "not a url" },
+		func(sub *Subscription) { sub.Endpoint = "http://fcm.googleapis.com/fcm/send/abc" },
+		func(sub *Subscription) { sub.Endpoint = "https://fcm.googleapis.com:8080/fcm/send/abc" },
+		func(sub *Subscription) { sub.Endpoint = "https://push.example.com/abc" },
+		func(sub *Subscription) { sub.Endpoint = "https://fcm.googleapis.com.example.com/abc" },
+		func(sub *Subscription) { sub.Endpoint = "https://169.254.169.254/latest" },
+		func(sub *Subscription) { sub.Endpoint = "https://user@fcm.googleapis.com/fcm/send/abc" },
+		func(sub *Subscription) { sub.Keys.Auth = "" },
+		func(sub *Subscription) { sub.Keys.P256dh = encodeBase64(ua.publicKey[1:]) },
+	}
+	require.NoError(t, ua.subscription("https://fcm.googleapis.com/fcm/send/abc").Validate(false))
+	require.NoError(t, ua.subscription("https://updates.push.services.mozilla.com/wpush/v2/abc").Validate(false))
+	for _, modify := range testCases {
+		sub := ua.subscription("https://fcm.googleapis.com/fcm/send/abc")
+		modify(sub)
+		require.ErrorIs(t, sub.Validate(false), ErrInvalidSubscription)
+	}
+}
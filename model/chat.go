@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+type Message struct {
+	ID       int64     `json:"id"`
+	GameID   int64     `json:"game_id"`
+	UserID   int64     `json:"user_id"`
+	Username string    `json:"username"`
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+type Mute struct {
+	UserID     int64     `json:"user_id"`
+	MutedBy    int64     `json:"muted_by"`
+	Reason     string    `json:"reason"`
+	MutedUntil time.Time `json:"muted_until"`
+}
+
+type Report struct {
+	ID               int64      `json:"id"`
+	MessageID        int64      `json:"message_id"`
+	MessageText      string     `json:"message_text"`
+	MessageAuthor    string     `json:"message_author"`
+	ReporterUsername string     `json:"reporter_username"`
+	Reason           string     `json:"reason"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ResolvedAt       *time.Time `json:"resolved_at"`
+}
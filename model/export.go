@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// GameRecord is a played game with moves in order, it's used for export.
+type GameRecord struct {
+	ID             int64
+	BlackUsername  string
+	WhiteUsername  string
+	WinnerUsername *string
+	Rated          bool
+	StartedAt      *time.Time
+	FinishedAt     *time.Time
+	Moves          []Move
+}
+
+// RatingChange is the ranking change after the rated game.
+type RatingChange struct {
+	GameID       int64     `json:"game_id"`
+	RatingBefore int       `json:"rating_before"`
+	RatingAfter  int       `json:"rating_after"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+type FriendshipStatus int
+
+const (
+	NotFriends FriendshipStatus = iota
+	RequestSent
+	RequestReceived
+	Friends
+)
+
+type FriendRequest struct {
+	UserID    int64     `json:"user_id"`
+	Username  string    `json:"username"`
+	Ranking   int       `json:"ranking"`
+	Incoming  bool      `json:"incoming"`
+	CreatedAt time.Time `json:"created_at"`
+}
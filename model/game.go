@@ -35,7 +35,10 @@ type Game struct {
 	Winner      *int64     `json:"winner_id"`
 	StartedAt   *time.Time `json:"started_at"`
 	Status      GameStatus `json:"status"`
+	Rated       bool       `json:"rated"`
 	FinishedAt  *time.Time `json:"finished_at"`
+	// BotLevel is set if one of the players is the bot.
+	BotLevel *int `json:"bot_level,omitempty"`
 
 	mu   sync.Mutex
 	game *pkggame.Game
@@ -59,6 +62,27 @@ func (g *Game) ApplyMove(userID int64, x, y int) (winner pkggame.Color, err erro
 	return
 }
 
+// Play applies the move like ApplyMove and describes its result.
+func (g *Game) Play(userID int64, x, y int) (result pkggame.MoveResult, err error) {
+	g.mu.Lock()
+	if g.game == nil {
+		g.game = pkggame.NewGame()
+	}
+	result, err = g.game.Play(pkggame.NewMove(x, y, g.GetColorByUserID(userID)))
+	g.mu.Unlock()
+	return
+}
+
+// ForbiddenPoints returns black's forbidden points of the applied moves.
+func (g *Game) ForbiddenPoints() []pkggame.ForbiddenPoint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.game == nil {
+		return nil
+	}
+	return g.game.ForbiddenPoints()
+}
+
 // GetColorByUserID ...
 func (g *Game) GetColorByUserID(userID int64) pkggame.Color {
 	switch userID {
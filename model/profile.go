@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Profile is shown to other users, except the settings.
+type Profile struct {
+	DisplayName string  `json:"display_name"`
+	Bio         string  `json:"bio"`
+	Country     *string `json:"country"`
+	// AvatarUpdatedAt is nil if user has no avatar.
+	AvatarUpdatedAt *time.Time `json:"-"`
+	Settings        Settings   `json:"-"`
+}
+
+// Settings are visible only to the owner.
+type Settings struct {
+	Language *string `json:"language"`
+	// BoardTheme is one of BoardThemes.
+	BoardTheme       string `json:"board_theme"`
+	BoardCoordinates bool   `json:"board_coordinates"`
+	MoveNumbers      bool   `json:"move_numbers"`
+}
+
+const DefaultBoardTheme = "classic"
+
+var BoardThemes = []string{DefaultBoardTheme, "wood", "dark", "contrast"}
@@ -1,12 +1,18 @@
 package model
 
+import "time"
+
 type User struct {
-	ID             int64   `json:"id"`
-	Username       string  `json:"username"`
-	Email          *string `json:"email"`
-	Ranking        int     `json:"ranking"`
-	PasswordBcrypt *string `json:"-"`
-	GoogleID       *string `json:"-"`
-	YandexID       *string `json:"-"`
-	VKID           *string `json:"-"`
+	ID            int64   `json:"id"`
+	Username      string  `json:"username"`
+	Email         *string `json:"email"`
+	Ranking       int     `json:"ranking"`
+	IsAdmin       bool    `json:"-"`
+	EmailVerified bool    `json:"-"`
+	IsBot         bool    `json:"is_bot"`
+	// TwoFactorEnabled requires TOTP or recovery code to sign in.
+	TwoFactorEnabled bool    `json:"-"`
+	PasswordBcrypt   *string `json:"-"`
+	// Tokens issued before this moment are not accepted.
+	SessionsRevokedAt *time.Time `json:"-"`
 }
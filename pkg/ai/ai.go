@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/renju24/backend/pkg/game"
+)
+
+// Level is the bot strength from MinLevel to MaxLevel.
+type Level int
+
+const (
+	MinLevel Level = 1
+	MaxLevel Level = 5
+)
+
+var (
+	ErrInvalidLevel = errors.New("ai: invalid level")
+	ErrNoMoves      = errors.New("ai: no legal moves")
+)
+
+// settings of the level: alpha-beta depth and width, continuous fours and threats depth and random noise of the root scores.
+type settings struct {
+	depth    int
+	width    int
+	vcfDepth int
+	vctDepth int
+	noise    int
+	timeout  time.Duration
+}
+
+var levels = map[Level]settings{
+	1: {depth: 1, width: 8, vcfDepth: 0, noise: 600, timeout: 200 * time.Millisecond},
+	2: {depth: 2, width: 8, vcfDepth: 0, noise: 150, timeout: 500 * time.Millisecond},
+	3: {depth: 2, width: 10, vcfDepth: 8, noise: 30, timeout: time.Second},
+	4: {depth: 3, width: 10, vcfDepth: 12, vctDepth: 2, noise: 0, timeout: 2 * time.Second},
+	5: {depth: 4, width: 12, vcfDepth: 16, vctDepth: 3, noise: 0, timeout: 3 * time.Second},
+}
+
+// Bot chooses moves with threat-space search and alpha-beta search. It's safe for concurrent use.
+type Bot struct {
+	level    Level
+	settings settings
+}
+
+func New(level Level) (*Bot, error) {
+	s, ok := levels[level]
+	if !ok {
+		return nil, ErrInvalidLevel
+	}
+	return &Bot{level: level, settings: s}, nil
+}
+
+func (b *Bot) Level() Level {
+	return b.level
+}
+
+// Move returns the move for the side to move. Black moves are never forbidden.
+func (b *Bot) Move(g *game.Game) (x, y int, err error) {
+	color := g.Turn()
+	pos := newBoard(g.Board())
+	// The first move must be in the center.
+	if pos.stones == 0 {
+		return size / 2, size / 2, nil
+	}
+	s := &search{
+		game:     g,
+		board:    pos,
+		settings: b.settings,
+		deadline: time.Now().Add(b.settings.timeout),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	point, ok := s.bestMove(color)
+	if !ok {
+		return 0, 0, ErrNoMoves
+	}
+	x, y = game.Coordinates(point)
+	return x, y, nil
+}
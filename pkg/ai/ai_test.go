@@ -0,0 +1,143 @@
+package ai
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"unicode"
+
+	"github.com/renju24/backend/pkg/game"
+	"github.com/stretchr/testify/require"
+)
+
+// Moves are written as in pkg/game tests: letter is the column, digit is the row,
+// upper-case letter is black move and lower-case letter is white move, i.e. H8 is black, h9 is white.
+
+var reg = regexp.MustCompile(`\w\d{1,2}`)
+
+func point(str string) (x, y int, c game.Color) {
+	c = game.White
+	r := rune(str[0])
+	if unicode.IsUpper(r) {
+		c = game.Black
+		r = unicode.ToLower(r)
+	}
+	row, _ := strconv.Atoi(str[1:])
+	return size - row, int(r - 'a'), c
+}
+
+// play applies the moves in order, so the position is legal.
+func play(t *testing.T, moves string) *game.Game {
+	g := game.NewGame()
+	for _, v := range reg.FindAllString(moves, -1) {
+		x, y, c := point(v)
+		_, err := g.ApplyMove(game.NewMove(x, y, c))
+		require.NoError(t, err, v)
+	}
+	return g
+}
+
+// position places the stones in any order.
+func position(stones string) *board {
+	var cells game.Board
+	for _, v := range reg.FindAllString(stones, -1) {
+		x, y, c := point(v)
+		cells[game.Index(x, y)] = c
+	}
+	return newBoard(cells)
+}
+
+func TestNew(t *testing.T) {
+	for level := MinLevel; level <= MaxLevel; level++ {
+		bot, err := New(level)
+		require.NoError(t, err)
+		require.Equal(t, level, bot.Level())
+	}
+	_, err := New(MinLevel - 1)
+	require.ErrorIs(t, err, ErrInvalidLevel)
+	_, err = New(MaxLevel + 1)
+	require.ErrorIs(t, err, ErrInvalidLevel)
+}
+
+func TestMove(t *testing.T) {
+	testCases := []struct {
+		name     string
+		moves    string
+		expected []string
+	}{
+		{
+			name:     "first move in the center",
+			moves:    "",
+			expected: []string{"H8"},
+		},
+		{
+			name:     "five instead of block",
+			moves:    "H8 h9 I8 i9 J8 j9 K8 k9",
+			expected: []string{"G8", "L8"},
+		},
+		{
+			name:     "block five",
+			moves:    "H8 h9 I8 i9 J8 g8 K8",
+			expected: []string{"l8"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := play(t, tc.moves)
+			for level := MinLevel; level <= MaxLevel; level++ {
+				bot, err := New(level)
+				require.NoError(t, err)
+				x, y, err := bot.Move(g)
+				require.NoError(t, err)
+				var found bool
+				for _, expected := range tc.expected {
+					ex, ey, _ := point(expected)
+					found = found || (ex == x && ey == y)
+				}
+				require.True(t, found, "level %d: %d %d", level, x, y)
+			}
+		})
+	}
+}
+
+func TestVCF(t *testing.T) {
+	// White wins with l11 (black must block m11) and l8, or the same moves in reverse order.
+	g := play(t, "H8 i8 H11 j8 L12 k8 A1 l9 A3 l10 A5 i11 O1 j11 O3 k11 O5")
+	for level := Level(3); level <= MaxLevel; level++ {
+		bot, err := New(level)
+		require.NoError(t, err)
+		x, y, err := bot.Move(g)
+		require.NoError(t, err)
+		require.Contains(t, []string{"l8", "l11"}, string(rune('a'+y))+strconv.Itoa(size-x), "level %d", level)
+	}
+}
+
+func TestForbiddenCandidates(t *testing.T) {
+	// I8 makes two open threes for black.
+	b := position("G8 H8 I9 I10 a1 b2 c3")
+	x, y, _ := point("I8")
+	require.True(t, game.Forbidden(b.cells, x, y))
+	require.NotContains(t, b.candidates(game.Black, size*size), game.Index(x, y))
+	require.Contains(t, b.candidates(game.White, size*size), game.Index(x, y))
+}
+
+func TestSelfPlay(t *testing.T) {
+	black, err := New(MinLevel)
+	require.NoError(t, err)
+	white, err := New(MinLevel + 1)
+	require.NoError(t, err)
+	g := game.NewGame()
+	for i := 0; i < 30; i++ {
+		bot := black
+		if g.Turn() == game.White {
+			bot = white
+		}
+		x, y, err := bot.Move(g)
+		require.NoError(t, err)
+		winner, err := g.ApplyMove(game.NewMove(x, y, g.Turn()))
+		require.NoError(t, err)
+		if winner != game.Nil {
+			break
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package ai
+
+import "github.com/renju24/backend/pkg/game"
+
+const (
+	size = game.BoardSize
+
+	// winScore is returned for the won position, it's larger than any evaluation.
+	winScore = 1 << 30
+)
+
+// windowWeights[n] is the value of five cells with n stones of one color and no stones of the other one.
+var windowWeights = [6]int{0, 1, 12, 150, 2000, winScore}
+
+// board is the search position, stones are placed and removed without rules checks.
+type board struct {
+	cells  game.Board
+	stones int
+}
+
+func newBoard(cells game.Board) *board {
+	b := &board{cells: cells}
+	for _, c := range cells {
+		if c != game.Nil {
+			b.stones++
+		}
+	}
+	return b
+}
+
+func (b *board) put(i int, c game.Color) {
+	b.cells[i] = c
+	b.stones++
+}
+
+func (b *board) remove(i int) {
+	b.cells[i] = game.Nil
+	b.stones--
+}
+
+// evaluate returns the static score of the position for c.
+func (b *board) evaluate(c game.Color) int {
+	var own, other int
+	for _, w := range game.Windows() {
+		var black, white int
+		for _, p := range w {
+			switch b.cells[p] {
+			case game.Black:
+				black++
+			case game.White:
+				white++
+			}
+		}
+		switch {
+		case black > 0 && white == 0:
+			if c == game.Black {
+				own += windowWeights[black]
+			} else {
+				other += windowWeights[black]
+			}
+		case white > 0 && black == 0:
+			if c == game.White {
+				own += windowWeights[white]
+			} else {
+				other += windowWeights[white]
+			}
+		}
+	}
+	return own - other
+}
+
+// pointScore estimates the move to the empty point for c: own windows it extends and opponent's windows it blocks.
+func (b *board) pointScore(i int, c game.Color) int {
+	var attack, defense int
+	for _, wi := range game.PointWindows(i) {
+		var own, other int
+		for _, p := range game.Windows()[wi] {
+			switch b.cells[p] {
+			case game.Nil:
+			case c:
+				own++
+			default:
+				other++
+			}
+		}
+		switch {
+		case other == 0:
+			attack += windowWeights[own+1] - windowWeights[own]
+		case own == 0:
+			defense += windowWeights[other+1] - windowWeights[other]
+		}
+	}
+	return attack + defense*9/10
+}
+
+// candidates returns empty points within two cells of the stones ordered by pointScore, at most limit points.
+func (b *board) candidates(c game.Color, limit int) []int {
+	type scored struct {
+		point, score int
+	}
+	var points []scored
+	for i, cell := range b.cells {
+		if cell != game.Nil || !b.hasNeighbour(i, 2) {
+			continue
+		}
+		points = append(points, scored{i, b.pointScore(i, c)})
+	}
+	// Insertion sort keeps equal points in board order, so the search is deterministic.
+	for i := 1; i < len(points); i++ {
+		for j := i; j > 0 && points[j].score > points[j-1].score; j-- {
+			points[j], points[j-1] = points[j-1], points[j]
+		}
+	}
+	result := make([]int, 0, limit)
+	for _, p := range points {
+		if len(result) == limit {
+			break
+		}
+		if game.Legal(&b.cells, p.point, c) {
+			result = append(result, p.point)
+		}
+	}
+	return result
+}
+
+func (b *board) hasNeighbour(i, distance int) bool {
+	x, y := game.Coordinates(i)
+	for dx := -distance; dx <= distance; dx++ {
+		for dy := -distance; dy <= distance; dy++ {
+			nx, ny := x+dx, y+dy
+			if (dx == 0 && dy == 0) || nx < 0 || nx >= size || ny < 0 || ny >= size {
+				continue
+			}
+			if b.cells[game.Index(nx, ny)] != game.Nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,169 @@
+package ai
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/renju24/backend/pkg/game"
+)
+
+// maxThreatNodes limits the threat-space search, it's usually much faster than the alpha-beta search.
+const maxThreatNodes = 20000
+
+type search struct {
+	game     *game.Game
+	board    *board
+	settings settings
+	deadline time.Time
+	rand     *rand.Rand
+	nodes    int
+	timedOut bool
+}
+
+func (s *search) expired() bool {
+	if !s.timedOut && s.nodes%256 == 0 && time.Now().After(s.deadline) {
+		s.timedOut = true
+	}
+	return s.timedOut
+}
+
+// bestMove wins if it's possible, blocks the opponent's five, looks for the victory by continuous fours
+// and otherwise chooses the move with alpha-beta search.
+func (s *search) bestMove(c game.Color) (int, bool) {
+	b := s.board
+	for _, p := range game.FivePoints(&b.cells, c) {
+		if game.Legal(&b.cells, p, c) {
+			return p, true
+		}
+	}
+	threats := game.FivePoints(&b.cells, game.Opposite(c))
+	for _, p := range threats {
+		if game.Legal(&b.cells, p, c) {
+			return p, true
+		}
+	}
+	if len(threats) == 0 && s.settings.vcfDepth > 0 {
+		if p, ok := s.threatWin(); ok {
+			return p, true
+		}
+	}
+	candidates := b.candidates(c, s.settings.width)
+	if len(candidates) == 0 {
+		for i := range b.cells {
+			if game.Legal(&b.cells, i, c) {
+				return i, true
+			}
+		}
+		return 0, false
+	}
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	best := candidates[0]
+	// Iterative deepening: the deeper iteration is used only if it's completed in time.
+	for depth := 1; depth <= s.settings.depth; depth++ {
+		move, ok := s.root(c, candidates, depth)
+		if !ok {
+			break
+		}
+		best = move
+	}
+	return best, true
+}
+
+// root scores all candidates, random noise weakens the lower levels, but it never hides a forced win or loss.
+func (s *search) root(c game.Color, candidates []int, depth int) (int, bool) {
+	b := s.board
+	best, bestScore := candidates[0], -2*winScore
+	for _, p := range candidates {
+		var score int
+		if game.MakesFive(&b.cells, p, c) {
+			score = winScore
+		} else {
+			b.put(p, c)
+			score = -s.negamax(game.Opposite(c), depth-1, -2*winScore, 2*winScore, 1)
+			b.remove(p)
+		}
+		// The first iteration is always completed.
+		if depth > 1 && s.timedOut {
+			return 0, false
+		}
+		if s.settings.noise > 0 && score > -winScore/2 && score < winScore/2 {
+			score += s.rand.Intn(2*s.settings.noise+1) - s.settings.noise
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best, true
+}
+
+// negamax returns the score of the position for c, which is to move.
+func (s *search) negamax(c game.Color, depth, alpha, beta, ply int) int {
+	b := s.board
+	s.nodes++
+	if s.expired() {
+		return 0
+	}
+	for _, p := range game.FivePoints(&b.cells, c) {
+		if game.Legal(&b.cells, p, c) {
+			return winScore - ply
+		}
+	}
+	var moves []int
+	if threats := game.FivePoints(&b.cells, game.Opposite(c)); len(threats) > 0 {
+		// Only one five can be blocked, the forbidden point can't be blocked by black at all.
+		if len(threats) > 1 || !game.Legal(&b.cells, threats[0], c) {
+			return -(winScore - ply - 1)
+		}
+		moves = threats
+	} else {
+		if depth <= 0 {
+			return b.evaluate(c)
+		}
+		moves = b.candidates(c, s.settings.width)
+		if len(moves) == 0 {
+			return 0
+		}
+	}
+	best := -2 * winScore
+	for _, p := range moves {
+		b.put(p, c)
+		score := -s.negamax(game.Opposite(c), depth-1, -beta, -alpha, ply+1)
+		b.remove(p)
+		if s.timedOut {
+			return 0
+		}
+		if score > best {
+			best = score
+		}
+		if score > alpha {
+			alpha = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	return best
+}
+
+// threatWin looks for the victory by continuous fours and then by continuous threats with the game solver.
+// It returns the first move of the winning sequence.
+func (s *search) threatWin() (int, bool) {
+	opts := game.SolveOptions{
+		MaxDepth: s.settings.vcfDepth,
+		MaxNodes: maxThreatNodes,
+		Timeout:  time.Until(s.deadline) / 2,
+	}
+	solution := s.game.SolveVCF(opts)
+	if solution.Result != game.Win && s.settings.vctDepth > 0 {
+		opts.MaxDepth = s.settings.vctDepth
+		opts.Timeout = time.Until(s.deadline) / 2
+		solution = s.game.SolveVCT(opts)
+	}
+	if solution.Result != game.Win {
+		return 0, false
+	}
+	m := solution.Moves[0]
+	return game.Index(m.X(), m.Y()), true
+}
@@ -0,0 +1,148 @@
+package game
+
+// ForbiddenReason explains why the point is forbidden for black.
+type ForbiddenReason string
+
+const (
+	Overline    ForbiddenReason = "overline"
+	DoubleThree ForbiddenReason = "double_three"
+	DoubleFour  ForbiddenReason = "double_four"
+)
+
+// ForbiddenPoint is the empty point where black can't move.
+type ForbiddenPoint struct {
+	X      int
+	Y      int
+	Reason ForbiddenReason
+}
+
+// Forbidden reports whether black stone on the empty point is banned: it makes overline or forbidden fork.
+// The point which makes exactly five is never forbidden.
+func Forbidden(board Board, x, y int) bool {
+	_, forbidden := forbiddenReason(board, x, y)
+	return forbidden
+}
+
+// ForbiddenPoints returns all points which are forbidden for black in the current position, whoever is to move.
+func (g *Game) ForbiddenPoints() []ForbiddenPoint {
+	var points []ForbiddenPoint
+	for i, c := range g.board {
+		if c != Nil || !nearBlackStones(&g.board, i) {
+			continue
+		}
+		x, y := Coordinates(i)
+		if reason, forbidden := forbiddenReason(g.board, x, y); forbidden {
+			points = append(points, ForbiddenPoint{X: x, Y: y, Reason: reason})
+		}
+	}
+	return points
+}
+
+// nearBlackStones reports whether the point is in the window with at least two black stones and no white ones,
+// the other points can't be forbidden.
+func nearBlackStones(board *Board, i int) bool {
+	for _, w := range pointWindows[i] {
+		if own, other := windowStones(board, windows[w], Black); own >= 2 && other == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// forbiddenReason checks the black move by RIF rules:
+//   - five is exactly five stones in a row, it wins even if the move also makes overline, double four or double three;
+//   - overline is six or more stones in a row;
+//   - four is the row which makes five with one more stone, X.XXX.X makes two fours in one line, but straight four .XXXX. is one four;
+//   - three is the row which makes straight four with one more stone, and this stone must not be forbidden itself.
+func forbiddenReason(board Board, x, y int) (ForbiddenReason, bool) {
+	i := x*BoardSize + y
+	if board[i] != Nil {
+		return "", false
+	}
+	board[i] = Black
+	g := Game{board: board}
+	overline := false
+	for _, offset := range allDirectionOffsets {
+		switch length := g.rowLength(NewMove(x, y, Black), offset); {
+		case length == 5:
+			return "", false
+		case length > 5:
+			overline = true
+		}
+	}
+	if overline {
+		return Overline, true
+	}
+	fours, threes := 0, 0
+	for _, offset := range allDirectionOffsets {
+		if n := fourCount(&board, i, offset); n > 0 {
+			fours += n
+		} else if isThree(&board, i, offset) {
+			threes++
+		}
+	}
+	switch {
+	case fours >= 2:
+		return DoubleFour, true
+	case threes >= 2:
+		return DoubleThree, true
+	}
+	return "", false
+}
+
+// fourCount returns the number of black fours through the stone on the point in the direction.
+func fourCount(board *Board, i, offset int) int {
+	points := lineFivePoints(board, i, offset, Black)
+	if len(points) == 2 && isStraightFour(board, points, offset, Black) {
+		return 1
+	}
+	return len(points)
+}
+
+// isStraightFour reports whether two five points are the ends of the same four stones of c.
+func isStraightFour(board *Board, points []int, offset int, c Color) bool {
+	from, to := points[0], points[1]
+	if from > to {
+		from, to = to, from
+	}
+	if to-from != 5*offset {
+		return false
+	}
+	for p := from + offset; p < to; p += offset {
+		if board[p] != c {
+			return false
+		}
+	}
+	return true
+}
+
+// isThree reports whether the black stone on the point makes true three in the direction:
+// one more stone which isn't forbidden makes straight four.
+func isThree(board *Board, i, offset int) bool {
+	for _, w := range pointWindows[i] {
+		window := windows[w]
+		if window[1]-window[0] != offset {
+			continue
+		}
+		if own, other := windowStones(board, window, Black); own != 3 || other != 0 {
+			continue
+		}
+		for _, p := range window {
+			if board[p] != Nil {
+				continue
+			}
+			board[p] = Black
+			points := lineFivePoints(board, i, offset, Black)
+			straight := len(points) == 2 && isStraightFour(board, points, offset, Black)
+			board[p] = Nil
+			if !straight {
+				continue
+			}
+			x, y := Coordinates(p)
+			if !Forbidden(*board, x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,192 @@
+package game
+
+import (
+	"bufio"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestForbiddenPositions checks testdata/forbidden.txt and the file from RENJU_FORBIDDEN_POSITIONS if it's set,
+// so other positions in the same format can be checked without adding them to the repository.
+func TestForbiddenPositions(t *testing.T) {
+	paths := []string{"testdata/forbidden.txt"}
+	if path := os.Getenv("RENJU_FORBIDDEN_POSITIONS"); path != "" {
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			checkForbiddenPositions(t, path)
+		})
+	}
+}
+
+// checkForbiddenPositions compares every line with forbiddenReason and with the reference implementation.
+func checkForbiddenPositions(t *testing.T, path string) {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	cases := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		require.Len(t, fields, 3, line)
+		g := initGame(fields[0])
+		m := moveFromStr(fields[1])
+		actual, reference := "legal", "legal"
+		if reason, forbidden := forbiddenReason(g.board, m.x, m.y); forbidden {
+			actual = string(reason)
+		}
+		if reason, forbidden := referenceForbidden(g.board, m.x, m.y); forbidden {
+			reference = string(reason)
+		}
+		require.Equal(t, fields[2], actual, line)
+		require.Equal(t, fields[2], reference, line)
+		cases++
+	}
+	require.NoError(t, scanner.Err())
+	require.NotZero(t, cases)
+}
+
+// TestForbiddenReference compares forbiddenReason with the straightforward implementation of RIF definitions
+// on random positions.
+func TestForbiddenReference(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		var board Board
+		// Stones are placed close to the random point, so there are many rows, also along the edges and in the corners.
+		cx, cy := r.Intn(BoardSize), r.Intn(BoardSize)
+		for stones := 10 + r.Intn(30); stones > 0; stones-- {
+			x, y := clamp(cx+r.Intn(9)-4), clamp(cy+r.Intn(9)-4)
+			c := Black
+			if r.Intn(5) < 2 {
+				c = White
+			}
+			board[x*BoardSize+y] = c
+		}
+		for i, c := range board {
+			if c != Nil {
+				continue
+			}
+			x, y := Coordinates(i)
+			expectedReason, expected := referenceForbidden(board, x, y)
+			reason, forbidden := forbiddenReason(board, x, y)
+			require.Equal(t, expected, forbidden, "position %d, point %d %d", n, x, y)
+			require.Equal(t, expectedReason, reason, "position %d, point %d %d", n, x, y)
+		}
+	}
+}
+
+func clamp(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v >= BoardSize {
+		return BoardSize - 1
+	}
+	return v
+}
+
+var referenceDirections = [4][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+func referenceForbidden(board Board, x, y int) (ForbiddenReason, bool) {
+	board[x*BoardSize+y] = Black
+	overline := false
+	for _, d := range referenceDirections {
+		switch n := referenceRow(&board, x, y, d); {
+		case n == 5:
+			return "", false
+		case n > 5:
+			overline = true
+		}
+	}
+	if overline {
+		return Overline, true
+	}
+	fours, threes := 0, 0
+	for _, d := range referenceDirections {
+		if n := referenceFours(&board, x, y, d); n > 0 {
+			fours += n
+			continue
+		}
+		for k := -4; k <= 4; k++ {
+			qx, qy := x+k*d[0], y+k*d[1]
+			if !referenceEmpty(&board, qx, qy) {
+				continue
+			}
+			board[qx*BoardSize+qy] = Black
+			straight := referenceStraightFour(&board, x, y, d)
+			board[qx*BoardSize+qy] = Nil
+			if !straight {
+				continue
+			}
+			if _, forbidden := referenceForbidden(board, qx, qy); !forbidden {
+				threes++
+				break
+			}
+		}
+	}
+	switch {
+	case fours >= 2:
+		return DoubleFour, true
+	case threes >= 2:
+		return DoubleThree, true
+	}
+	return "", false
+}
+
+// referenceRow is the number of black stones in a row through the stone.
+func referenceRow(board *Board, x, y int, d [2]int) int {
+	n := 1
+	for _, sign := range [2]int{-1, 1} {
+		for k := 1; ; k++ {
+			px, py := x+sign*k*d[0], y+sign*k*d[1]
+			if px < 0 || px >= BoardSize || py < 0 || py >= BoardSize || board[px*BoardSize+py] != Black {
+				break
+			}
+			n++
+		}
+	}
+	return n
+}
+
+func referenceEmpty(board *Board, x, y int) bool {
+	return x >= 0 && x < BoardSize && y >= 0 && y < BoardSize && board[x*BoardSize+y] == Nil
+}
+
+// referenceFivePoints returns the offsets of the points in the line which make exactly five with the stone.
+func referenceFivePoints(board *Board, x, y int, d [2]int) []int {
+	var points []int
+	for k := -4; k <= 4; k++ {
+		qx, qy := x+k*d[0], y+k*d[1]
+		if !referenceEmpty(board, qx, qy) {
+			continue
+		}
+		board[qx*BoardSize+qy] = Black
+		if referenceRow(board, x, y, d) == 5 {
+			points = append(points, k)
+		}
+		board[qx*BoardSize+qy] = Nil
+	}
+	return points
+}
+
+func referenceFours(board *Board, x, y int, d [2]int) int {
+	points := referenceFivePoints(board, x, y, d)
+	if len(points) == 2 && points[1]-points[0] == 5 {
+		return 1
+	}
+	return len(points)
+}
+
+func referenceStraightFour(board *Board, x, y int, d [2]int) bool {
+	points := referenceFivePoints(board, x, y, d)
+	return len(points) == 2 && points[1]-points[0] == 5
+}
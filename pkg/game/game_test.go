@@ -65,98 +65,86 @@ func printField(g *Game) {
 
 func TestForks(t *testing.T) {
 	testCases := []struct {
-		iniStr        string
-		move          Move
-		expectedError error
+		iniStr string
+		move   Move
+		// forbidden is true for double three and double four, overline is checked by ApplyMove separately.
+		forbidden bool
 	}{
 		{
-			iniStr:        "G9I9j9H8H7k4j3i2",
-			move:          moveFromStr("H9"),
-			expectedError: nil,
+			iniStr: "G9I9j9H8H7k4j3i2",
+			move:   moveFromStr("H9"),
 		},
 		{
-			iniStr:        "c12d11c10d9i14I12I11H8F6I6",
-			move:          moveFromStr("I9"),
-			expectedError: apierror.ErrInvalidForkForBlack,
+			iniStr:    "c12d11c10d9i14I12I11H8F6I6",
+			move:      moveFromStr("I9"),
+			forbidden: true,
 		},
 		{
-			iniStr:        "D10E9F8F6E5H8i8i7j9j8j6k5",
-			move:          moveFromStr("G7"),
-			expectedError: apierror.ErrInvalidForkForBlack,
+			iniStr:    "D10E9F8F6E5H8i8i7j9j8j6k5",
+			move:      moveFromStr("G7"),
+			forbidden: true,
 		},
 		{
-			iniStr:        "j12H8G7i8J8J7g3j3",
-			move:          moveFromStr("J10"),
-			expectedError: apierror.ErrInvalidForkForBlack,
+			iniStr:    "j12H8G7i8J8J7g3j3",
+			move:      moveFromStr("J10"),
+			forbidden: true,
 		},
 		{
-			iniStr:        "D8F8H8K8G6G5d2e1f2g1h2i1",
-			move:          moveFromStr("G8"),
-			expectedError: nil,
+			iniStr: "D8F8H8K8G6G5d2e1f2g1h2i1",
+			move:   moveFromStr("G8"),
 		},
 		{
-			iniStr:        "E8G8H8J8J9L8G6F5e2f2g2i2j2h1i1j1",
-			move:          moveFromStr("I8"),
-			expectedError: nil,
+			iniStr: "E8G8H8J8J9L8G6F5e2f2g2i2j2h1i1j1",
+			move:   moveFromStr("I8"),
 		},
-		{ // todo
-			iniStr:        "I9E8H8J8M8I6d2f2g2h1i1j1",
-			move:          moveFromStr("I8"),
-			expectedError: nil,
+		{ // the three in the row is false: both its fours make overline instead of straight four
+			iniStr: "I9E8H8J8M8I6d2f2g2h1i1j1",
+			move:   moveFromStr("I8"),
 		},
 		{
-			iniStr:        "C7E7H7",
-			move:          moveFromStr("F7"),
-			expectedError: nil,
+			iniStr: "C7E7H7",
+			move:   moveFromStr("F7"),
 		},
 		{
-			iniStr:        "B7C7E7H7",
-			move:          moveFromStr("F7"),
-			expectedError: nil,
+			iniStr: "B7C7E7H7",
+			move:   moveFromStr("F7"),
 		},
-		{
-			iniStr:        "B7C7E7H7I7",
-			move:          moveFromStr("F7"),
-			expectedError: nil,
+		{ // two fours in one line: D7 and G7 both make five
+			iniStr:    "B7C7E7H7I7",
+			move:      moveFromStr("F7"),
+			forbidden: true,
 		},
 		{
-			iniStr:        "C11F10H10J10J9K9F8H8K8K7G6H6I6G4J4",
-			move:          moveFromStr("I7"),
-			expectedError: nil,
+			iniStr: "C11F10H10J10J9K9F8H8K8K7G6H6I6G4J4",
+			move:   moveFromStr("I7"),
 		},
 		{
-			iniStr:        "H10H9J9G8H8J8K8L8F6G6H6J6K6H5J5J4",
-			move:          moveFromStr("I7"),
-			expectedError: nil,
+			iniStr: "H10H9J9G8H8J8K8L8F6G6H6J6K6H5J5J4",
+			move:   moveFromStr("I7"),
 		},
 		{
-			iniStr:        "L12f11F10F9I9F8H8E7F7H6F5F3F2f1",
-			move:          moveFromStr("H9"),
-			expectedError: nil,
+			iniStr: "L12f11F10F9I9F8H8E7F7H6F5F3F2f1",
+			move:   moveFromStr("H9"),
 		},
 		{
-			iniStr:        "E11E9G9I9H8E7F7E6H6J6",
-			move:          moveFromStr("H7"),
-			expectedError: nil,
+			iniStr: "E11E9G9I9H8E7F7E6H6J6",
+			move:   moveFromStr("H7"),
 		},
 		{
-			iniStr:        "G9H9I9F8H8J8G7I7G6I6",
-			move:          moveFromStr("H6"),
-			expectedError: nil,
+			iniStr: "G9H9I9F8H8J8G7I7G6I6",
+			move:   moveFromStr("H6"),
 		},
 		{
-			iniStr:        "E11E9G9I9H8E7F7E6H6J6J4",
-			move:          moveFromStr("H7"),
-			expectedError: apierror.ErrInvalidForkForBlack,
+			iniStr:    "E11E9G9I9H8E7F7E6H6J6J4",
+			move:      moveFromStr("H7"),
+			forbidden: true,
 		},
 	}
 
 	for _, testCase := range testCases {
 		g := initGame(testCase.iniStr)
-		// g.board[testCase.move.x*BoardSize+testCase.move.y] = testCase.move.color
-		// printField(g)
-		actualErr := g.checkForFork(testCase.move)
-		require.ErrorIs(t, testCase.expectedError, actualErr)
+		reason, forbidden := forbiddenReason(g.board, testCase.move.x, testCase.move.y)
+		require.Equal(t, testCase.forbidden, forbidden && reason != Overline, testCase.iniStr)
 	}
 }
 
@@ -289,3 +277,111 @@ func TestGame(t *testing.T) {
 		}
 	}
 }
+
+func TestForbiddenPoints(t *testing.T) {
+	testCases := []struct {
+		iniStr   string
+		expected []string
+		reason   ForbiddenReason
+	}{
+		{
+			iniStr:   "F8G8H9H10",
+			expected: []string{"H8"},
+			reason:   DoubleThree,
+		},
+		{
+			iniStr:   "H8I8J8L8M8",
+			expected: []string{"K8"},
+			reason:   Overline,
+		},
+		{
+			iniStr:   "H8I8K8L9L10L12",
+			expected: []string{"L8"},
+			reason:   DoubleFour,
+		},
+		{
+			iniStr:   "H8h9",
+			expected: nil,
+		},
+	}
+	for _, testCase := range testCases {
+		var expected []ForbiddenPoint
+		for _, v := range testCase.expected {
+			m := moveFromStr(v)
+			expected = append(expected, ForbiddenPoint{X: m.x, Y: m.y, Reason: testCase.reason})
+		}
+		require.Equal(t, expected, initGame(testCase.iniStr).ForbiddenPoints(), testCase.iniStr)
+	}
+}
+
+func TestPlay(t *testing.T) {
+	points := func(moves ...string) []Point {
+		var result []Point
+		for _, v := range moves {
+			m := moveFromStr(v)
+			result = append(result, Point{X: m.x, Y: m.y})
+		}
+		return result
+	}
+	testCases := []struct {
+		iniStr   string
+		move     Move
+		expected MoveResult
+	}{
+		{
+			iniStr: "H8h9I8i9J8j9K8k9",
+			move:   moveFromStr("L8"),
+			expected: MoveResult{
+				Winner:      Black,
+				WinningLine: points("H8", "I8", "J8", "K8", "L8"),
+			},
+		},
+		{
+			iniStr: "H8h9I8i9J8",
+			move:   moveFromStr("j9"),
+			expected: MoveResult{
+				OpenThrees: []Threat{{Stones: points("h9", "i9", "j9"), Points: points("g9", "k9")}},
+				Forced:     true,
+			},
+		},
+		{
+			// c9 blocks the four d9-g9, and h9 makes d9 f9 g9 h9 j9 with two fours but no straight four.
+			iniStr: "d9f9j9C9",
+			move:   moveFromStr("g9"),
+		},
+		{
+			iniStr: "H8h9I8i9J8g8",
+			move:   moveFromStr("K8"),
+			expected: MoveResult{
+				Fours:  []Threat{{Stones: points("H8", "I8", "J8", "K8"), Points: points("L8")}},
+				Forced: true,
+			},
+		},
+		{
+			iniStr: "H8h9I8i9J8",
+			move:   moveFromStr("a1"),
+		},
+	}
+	for _, testCase := range testCases {
+		result, err := initGame(testCase.iniStr).Play(testCase.move)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, result, testCase.iniStr)
+	}
+}
+
+func TestRowsDontWrap(t *testing.T) {
+	// a13-d10 and o15 are on different diagonals, o15 is index 14 and the next diagonal step wraps to a13.
+	for _, c := range []Color{Black, White} {
+		g := initGame("a13b12c11d10")
+		for i, v := range g.board {
+			if v != Nil {
+				g.board[i] = c
+			}
+		}
+		m := moveFromStr("o15")
+		require.False(t, MakesFive(&g.board, m.x*BoardSize+m.y, c))
+	}
+	g := initGame("m15n15o15")
+	m := moveFromStr("a14")
+	require.Equal(t, 1, g.rowLength(NewMove(m.x, m.y, White), horizontalOffset))
+}
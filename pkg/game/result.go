@@ -0,0 +1,176 @@
+package game
+
+import "sort"
+
+// Point is the intersection of the board.
+type Point struct {
+	X int
+	Y int
+}
+
+// Threat is the row made by the move in one direction.
+type Threat struct {
+	// Stones of the row including the move.
+	Stones []Point
+	// Points complete the row: to five for four, to open four for three.
+	// Four with two points can't be blocked.
+	Points []Point
+}
+
+// MoveResult describes the applied move.
+type MoveResult struct {
+	Winner Color
+	// WinningLine is the row of five or more stones, it's set if there is a winner.
+	WinningLine []Point
+	// Fours and OpenThrees are made by the move, they are not set if there is a winner.
+	Fours      []Threat
+	OpenThrees []Threat
+	// Forced is true if the opponent must respond to the four or the open three.
+	Forced bool
+}
+
+// Play applies the move like ApplyMove and describes its result.
+func (g *Game) Play(move Move) (MoveResult, error) {
+	winner, err := g.ApplyMove(move)
+	if err != nil {
+		return MoveResult{}, err
+	}
+	result := MoveResult{Winner: winner}
+	i := move.x*BoardSize + move.y
+	if winner != Nil {
+		result.WinningLine = g.winningLine(i, winner)
+		return result, nil
+	}
+	for _, offset := range allDirectionOffsets {
+		if fives := lineFivePoints(&g.board, i, offset, move.color); len(fives) > 0 {
+			result.Fours = append(result.Fours, g.threat(i, offset, fives))
+			continue
+		}
+		if points := g.openFourPoints(i, offset, move.color); len(points) > 0 {
+			result.OpenThrees = append(result.OpenThrees, g.threat(i, offset, points))
+		}
+	}
+	result.Forced = len(result.Fours) > 0 || len(result.OpenThrees) > 0
+	return result, nil
+}
+
+// winningLine returns the stones of the row of five through the point.
+func (g *Game) winningLine(i int, c Color) []Point {
+	for _, offset := range allDirectionOffsets {
+		x, y := Coordinates(i)
+		length := g.rowLength(NewMove(x, y, c), offset)
+		if length < 5 || (length > 5 && c == Black) {
+			continue
+		}
+		line := []Point{{X: x, Y: y}}
+		for _, dir := range [2]int{-1, 1} {
+			curIndex := i
+			for {
+				var err error
+				curIndex, err = nextIndex(curIndex, offset*dir)
+				if err != nil || g.board[curIndex] != c {
+					break
+				}
+				x, y := Coordinates(curIndex)
+				if dir < 0 {
+					line = append([]Point{{X: x, Y: y}}, line...)
+				} else {
+					line = append(line, Point{X: x, Y: y})
+				}
+			}
+		}
+		return line
+	}
+	return nil
+}
+
+// threat collects the stones of the windows through the point in the direction which contain the completing points.
+func (g *Game) threat(i, offset int, points []int) Threat {
+	var t Threat
+	c := g.board[i]
+	var stones []int
+	for _, w := range pointWindows[i] {
+		window := windows[w]
+		if window[1]-window[0] != offset {
+			continue
+		}
+		if _, other := windowStones(&g.board, window, c); other > 0 {
+			continue
+		}
+		var completes bool
+		for _, p := range window {
+			completes = completes || containsPoint(points, p)
+		}
+		if !completes {
+			continue
+		}
+		for _, p := range window {
+			if g.board[p] == c && !containsPoint(stones, p) {
+				stones = append(stones, p)
+			}
+		}
+	}
+	sort.Ints(stones)
+	sort.Ints(points)
+	for _, p := range stones {
+		x, y := Coordinates(p)
+		t.Stones = append(t.Stones, Point{X: x, Y: y})
+	}
+	for _, p := range points {
+		x, y := Coordinates(p)
+		t.Points = append(t.Points, Point{X: x, Y: y})
+	}
+	return t
+}
+
+// openFourPoints returns the legal points where c makes straight four through the point in the direction.
+func (g *Game) openFourPoints(i, offset int, c Color) []int {
+	var points []int
+	for _, w := range pointWindows[i] {
+		window := windows[w]
+		if window[1]-window[0] != offset {
+			continue
+		}
+		if own, other := windowStones(&g.board, window, c); own != 3 || other != 0 {
+			continue
+		}
+		for _, p := range window {
+			if g.board[p] != Nil || containsPoint(points, p) || !Legal(&g.board, p, c) {
+				continue
+			}
+			g.board[p] = c
+			// Two fours in one line like X.XXX.X are not open four.
+			fives := lineFivePoints(&g.board, i, offset, c)
+			if len(fives) == 2 && isStraightFour(&g.board, fives, offset, c) {
+				points = append(points, p)
+			}
+			g.board[p] = Nil
+		}
+	}
+	return points
+}
+
+// lineFivePoints returns the empty points where c makes five with the stone on the point in the direction.
+func lineFivePoints(board *Board, i, offset int, c Color) []int {
+	g := Game{board: *board}
+	var points []int
+	for _, w := range pointWindows[i] {
+		window := windows[w]
+		if window[1]-window[0] != offset {
+			continue
+		}
+		if own, other := windowStones(board, window, c); own != 4 || other != 0 {
+			continue
+		}
+		for _, p := range window {
+			if board[p] != Nil || containsPoint(points, p) {
+				continue
+			}
+			x, y := Coordinates(p)
+			if length := g.rowLength(NewMove(x, y, c), offset); length == 5 || (length > 5 && c == White) {
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
@@ -0,0 +1,443 @@
+package game
+
+import "time"
+
+// SolveResult is the outcome of the threat search.
+type SolveResult int
+
+const (
+	// NoWin means there is no winning sequence within the depth.
+	NoWin SolveResult = iota
+	// Win means the side to move wins whatever the opponent does.
+	Win
+	// Unknown means the node or time budget is exhausted before the search is completed.
+	Unknown
+)
+
+const (
+	defaultVCFDepth = 20
+	defaultVCTDepth = 4
+	defaultMaxNodes = 100000
+)
+
+// SolveOptions limit the search. Zero values use the defaults.
+type SolveOptions struct {
+	// MaxDepth is the maximal number of the attacker's moves.
+	MaxDepth int
+	// MaxNodes is the maximal number of the attacker's moves tried.
+	MaxNodes int
+	// Timeout is not limited by default.
+	Timeout time.Duration
+}
+
+// Solution is the winning sequence: the attacker's moves and the defender's replies in turn.
+// The attacker's last move makes five or the threat which can't be defended.
+type Solution struct {
+	Result SolveResult
+	Moves  []Move
+	Nodes  int
+}
+
+// SolveVCF looks for the victory by continuous fours of the side to move: every attacker's move makes four,
+// so the defender's reply is forced. Black's forbidden points are never played by black and can't be used by black to defend.
+func (g *Game) SolveVCF(opts SolveOptions) Solution {
+	return g.solve(opts, defaultVCFDepth, false)
+}
+
+// SolveVCT looks for the victory by continuous threats: fours and threes. The defender tries all replies
+// which stop the three and all counter fours.
+func (g *Game) SolveVCT(opts SolveOptions) Solution {
+	return g.solve(opts, defaultVCTDepth, true)
+}
+
+func (g *Game) solve(opts SolveOptions, defaultDepth int, threes bool) Solution {
+	s := &solver{
+		board:    g.board,
+		attacker: g.Turn(),
+		defender: Opposite(g.Turn()),
+		threes:   threes,
+		maxNodes: opts.MaxNodes,
+	}
+	if s.maxNodes <= 0 {
+		s.maxNodes = defaultMaxNodes
+	}
+	if opts.Timeout > 0 {
+		s.deadline = time.Now().Add(opts.Timeout)
+	}
+	depth := opts.MaxDepth
+	if depth <= 0 {
+		depth = defaultDepth
+	}
+	moves, win := s.attack(depth)
+	switch {
+	case win:
+		return Solution{Result: Win, Moves: moves, Nodes: s.nodes}
+	case s.exhausted:
+		return Solution{Result: Unknown, Nodes: s.nodes}
+	}
+	return Solution{Result: NoWin, Nodes: s.nodes}
+}
+
+// Opposite returns the other player's color.
+func Opposite(c Color) Color {
+	if c == Black {
+		return White
+	}
+	return Black
+}
+
+type solver struct {
+	board     Board
+	attacker  Color
+	defender  Color
+	threes    bool
+	maxNodes  int
+	deadline  time.Time
+	nodes     int
+	exhausted bool
+}
+
+func (s *solver) move(i int, c Color) Move {
+	x, y := Coordinates(i)
+	return NewMove(x, y, c)
+}
+
+// budget counts the node and reports whether the search may continue.
+func (s *solver) budget() bool {
+	if s.exhausted {
+		return false
+	}
+	s.nodes++
+	if s.nodes > s.maxNodes || (!s.deadline.IsZero() && s.nodes%1024 == 0 && time.Now().After(s.deadline)) {
+		s.exhausted = true
+	}
+	return !s.exhausted
+}
+
+// attack is the attacker's turn, it returns the winning sequence.
+func (s *solver) attack(depth int) ([]Move, bool) {
+	if fives := FivePoints(&s.board, s.attacker); len(fives) > 0 {
+		return []Move{s.move(fives[0], s.attacker)}, true
+	}
+	if depth == 0 {
+		return nil, false
+	}
+	// The defender's four must be blocked first, the attack goes on if the block is a threat itself.
+	if counter := FivePoints(&s.board, s.defender); len(counter) > 0 {
+		if !s.budget() {
+			return nil, false
+		}
+		return s.block(counter, depth)
+	}
+	candidates := fourPoints(&s.board, s.attacker)
+	fours := len(candidates)
+	if s.threes {
+		candidates = append(candidates, threePoints(&s.board, s.attacker)...)
+	}
+	for n, p := range candidates {
+		if !s.budget() {
+			return nil, false
+		}
+		s.board[p] = s.attacker
+		var (
+			line []Move
+			win  bool
+		)
+		// The three which is also four is already checked.
+		if n < fours || len(FivePoints(&s.board, s.attacker)) == 0 {
+			line, win = s.defend(depth)
+		}
+		s.board[p] = Nil
+		if win {
+			return append([]Move{s.move(p, s.attacker)}, line...), true
+		}
+	}
+	return nil, false
+}
+
+// defend is the defender's turn after the attacker's threat, it returns the main line if all replies lose.
+func (s *solver) defend(depth int) ([]Move, bool) {
+	if len(FivePoints(&s.board, s.defender)) > 0 {
+		return nil, false
+	}
+	fives := FivePoints(&s.board, s.attacker)
+	switch {
+	case len(fives) >= 2:
+		return nil, true
+	case len(fives) == 1:
+		q := fives[0]
+		// Black can't block on the forbidden point.
+		if !Legal(&s.board, q, s.defender) {
+			return nil, true
+		}
+		s.board[q] = s.defender
+		line, win := s.attack(depth - 1)
+		s.board[q] = Nil
+		if win {
+			return append([]Move{s.move(q, s.defender)}, line...), true
+		}
+		return nil, false
+	}
+	// The three: the attacker's next move makes open four or double four.
+	if len(winningPoints(&s.board, s.attacker)) == 0 {
+		return nil, false
+	}
+	var mainLine []Move
+	for _, r := range s.defenses() {
+		if !s.budget() {
+			return nil, false
+		}
+		s.board[r] = s.defender
+		var (
+			line []Move
+			win  bool
+		)
+		if counter := FivePoints(&s.board, s.defender); len(counter) > 0 {
+			// The counter four must be blocked, then the defender moves again.
+			line, win = s.block(counter, depth)
+		} else {
+			line, win = s.attack(depth - 1)
+		}
+		s.board[r] = Nil
+		if !win {
+			return nil, false
+		}
+		if mainLine == nil {
+			mainLine = append([]Move{s.move(r, s.defender)}, line...)
+		}
+	}
+	return mainLine, true
+}
+
+// block answers the defender's four. The attack goes on only if the block makes four, or three in VCT.
+func (s *solver) block(counter []int, depth int) ([]Move, bool) {
+	if len(counter) > 1 {
+		return nil, false
+	}
+	q := counter[0]
+	if MakesFive(&s.board, q, s.attacker) {
+		return []Move{s.move(q, s.attacker)}, true
+	}
+	if !Legal(&s.board, q, s.attacker) {
+		return nil, false
+	}
+	s.board[q] = s.attacker
+	if !s.threes && len(FivePoints(&s.board, s.attacker)) == 0 {
+		s.board[q] = Nil
+		return nil, false
+	}
+	line, win := s.defend(depth)
+	s.board[q] = Nil
+	if win {
+		return append([]Move{s.move(q, s.attacker)}, line...), true
+	}
+	return nil, false
+}
+
+// defenses are the defender's replies to the three: the points of the attacker's windows which make
+// open four or double four, and the defender's counter fours.
+func (s *solver) defenses() []int {
+	seen := make(map[int]bool)
+	var points []int
+	add := func(p int) {
+		if !seen[p] && Legal(&s.board, p, s.defender) {
+			seen[p] = true
+			points = append(points, p)
+		}
+	}
+	for _, w := range winningPoints(&s.board, s.attacker) {
+		add(w)
+		for _, window := range pointWindows[w] {
+			own, other := windowStones(&s.board, windows[window], s.attacker)
+			if other > 0 || own < 2 {
+				continue
+			}
+			for _, p := range windows[window] {
+				if s.board[p] == Nil {
+					add(p)
+				}
+			}
+		}
+	}
+	for _, p := range fourPoints(&s.board, s.defender) {
+		add(p)
+	}
+	return points
+}
+
+// windows are all five cell segments of the board by indexes.
+var windows [][5]int
+
+// pointWindows are the windows containing the point.
+var pointWindows [BoardSize * BoardSize][]int
+
+func init() {
+	steps := [4][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+	for x := 0; x < BoardSize; x++ {
+		for y := 0; y < BoardSize; y++ {
+			for _, d := range steps {
+				endX, endY := x+4*d[0], y+4*d[1]
+				if endX < 0 || endX >= BoardSize || endY < 0 || endY >= BoardSize {
+					continue
+				}
+				var w [5]int
+				for i := range w {
+					w[i] = Index(x+i*d[0], y+i*d[1])
+				}
+				for _, p := range w {
+					pointWindows[p] = append(pointWindows[p], len(windows))
+				}
+				windows = append(windows, w)
+			}
+		}
+	}
+}
+
+// Windows returns all five cell segments of the board, they must not be modified.
+func Windows() [][5]int {
+	return windows
+}
+
+// PointWindows returns indexes in Windows of the segments containing the point.
+func PointWindows(i int) []int {
+	return pointWindows[i]
+}
+
+func windowStones(board *Board, w [5]int, c Color) (own, other int) {
+	for _, p := range w {
+		switch board[p] {
+		case Nil:
+		case c:
+			own++
+		default:
+			other++
+		}
+	}
+	return own, other
+}
+
+// MakesFive reports whether c wins by the move to the empty point. Black wins only by exactly five.
+func MakesFive(board *Board, i int, c Color) bool {
+	g := Game{board: *board}
+	x, y := Coordinates(i)
+	for _, offset := range allDirectionOffsets {
+		length := g.rowLength(NewMove(x, y, c), offset)
+		if length == 5 || (length > 5 && c == White) {
+			return true
+		}
+	}
+	return false
+}
+
+// Legal reports whether c can move to the empty point.
+func Legal(board *Board, i int, c Color) bool {
+	if board[i] != Nil {
+		return false
+	}
+	if c == White {
+		return true
+	}
+	x, y := Coordinates(i)
+	return !Forbidden(*board, x, y)
+}
+
+// FivePoints returns the empty points where c makes five.
+func FivePoints(board *Board, c Color) []int {
+	var points []int
+	for _, w := range windows {
+		own, other := windowStones(board, w, c)
+		if own != 4 || other != 0 {
+			continue
+		}
+		for _, p := range w {
+			if board[p] == Nil && !containsPoint(points, p) && MakesFive(board, p, c) {
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
+
+// fourPoints returns the legal points where c makes four.
+func fourPoints(board *Board, c Color) []int {
+	return threatPoints(board, c, 3, func() bool {
+		return len(FivePoints(board, c)) > 0
+	})
+}
+
+// threePoints returns the legal points where c makes three, i.e. the next move would make open four or double four.
+// Fours are not included.
+func threePoints(board *Board, c Color) []int {
+	return threatPoints(board, c, 2, func() bool {
+		return len(FivePoints(board, c)) == 0 && len(winningPoints(board, c)) > 0
+	})
+}
+
+// winningPoints returns the legal points where c makes five or two fives at once.
+func winningPoints(board *Board, c Color) []int {
+	points := threatPoints(board, c, 3, func() bool {
+		return len(FivePoints(board, c)) >= 2
+	})
+	for _, p := range FivePoints(board, c) {
+		if !containsPoint(points, p) {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+// threatPoints returns the legal empty points of the windows with stones of c only, at least minStones of them,
+// for which threat is true after the move.
+func threatPoints(board *Board, c Color, minStones int, threat func() bool) []int {
+	var candidates, points []int
+	for _, w := range windows {
+		own, other := windowStones(board, w, c)
+		if other != 0 || own < minStones || own == 5 {
+			continue
+		}
+		for _, p := range w {
+			if board[p] == Nil && !containsPoint(candidates, p) {
+				candidates = append(candidates, p)
+			}
+		}
+	}
+	for _, p := range candidates {
+		if !Legal(board, p, c) {
+			continue
+		}
+		board[p] = c
+		ok := threat()
+		board[p] = Nil
+		if ok {
+			points = append(points, p)
+		}
+	}
+	return points
+}
+
+func containsPoint(points []int, p int) bool {
+	for _, point := range points {
+		if point == p {
+			return true
+		}
+	}
+	return false
+}
+
+// rowLength is the length of the row through the move in one direction, the move's point is counted as c.
+func (g *Game) rowLength(m Move, offset int) int {
+	startIndex := m.x*BoardSize + m.y
+	length := 1
+	for _, dir := range [2]int{-1, 1} {
+		curIndex := startIndex
+		for {
+			var err error
+			curIndex, err = nextIndex(curIndex, offset*dir)
+			if err != nil || g.board[curIndex] != m.color {
+				break
+			}
+			length++
+		}
+	}
+	return length
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Positions are set by initGame, the side to move is the opposite of the last stone.
+
+func TestSolveVCF(t *testing.T) {
+	testCases := []struct {
+		name     string
+		iniStr   string
+		depth    int
+		result   SolveResult
+		expected []string
+	}{
+		{
+			name:     "five",
+			iniStr:   "h9i9j9k9H8I8J8K8",
+			result:   Win,
+			expected: []string{"g9", "l9"},
+		},
+		{
+			name:     "two fours",
+			iniStr:   "i8j8k8l9l10i11j11k11H11L12H8",
+			result:   Win,
+			expected: []string{"l8", "l11"},
+		},
+		{
+			name:   "too shallow",
+			iniStr: "i8j8k8l9l10i11j11k11H11L12H8",
+			depth:  1,
+			result: NoWin,
+		},
+		{
+			name:   "no fours",
+			iniStr: "H8",
+			result: NoWin,
+		},
+		{
+			// Black must block on h8, but it's the double three.
+			name:     "forbidden block",
+			iniStr:   "i9j10k11F8G8H9H10M13",
+			result:   Win,
+			expected: []string{"l12"},
+		},
+		{
+			// Black must block on k8, but it makes overline.
+			name:     "forbidden overline block",
+			iniStr:   "k9k10k11H8I8J8L8M8K13",
+			result:   Win,
+			expected: []string{"k12"},
+		},
+		{
+			// K8 makes two fours, so black can't use it.
+			name:   "forbidden double four",
+			iniStr: "H8I8J8K9K10K11g8k12",
+			result: NoWin,
+		},
+		{
+			// White must block black's four on a5, the block makes two fours.
+			name:     "block makes double four",
+			iniStr:   "b5c5d5b6c7d8A1A2A3A4",
+			result:   Win,
+			expected: []string{"a5"},
+		},
+		{
+			name:   "block makes no four",
+			iniStr: "b5c5b6c7A1A2A3A4",
+			result: NoWin,
+		},
+		{
+			name:     "double four for white",
+			iniStr:   "h8i8j8k9k10k11G8K12",
+			result:   Win,
+			expected: []string{"k8"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := initGame(tc.iniStr)
+			solution := g.SolveVCF(SolveOptions{MaxDepth: tc.depth})
+			require.Equal(t, tc.result, solution.Result)
+			if tc.result != Win {
+				require.Empty(t, solution.Moves)
+				return
+			}
+			requireWinningLine(t, g, solution.Moves)
+			var found bool
+			for _, expected := range tc.expected {
+				found = found || solution.Moves[0] == moveFromStr(expected)
+			}
+			require.True(t, found, solution.Moves[0])
+		})
+	}
+}
+
+func TestSolveVCT(t *testing.T) {
+	// h8 makes two open threes for white.
+	g := initGame("f8g8h9h10A15O1")
+	require.Equal(t, NoWin, g.SolveVCF(SolveOptions{}).Result)
+
+	solution := g.SolveVCT(SolveOptions{})
+	require.Equal(t, Win, solution.Result)
+	requireWinningLine(t, g, solution.Moves)
+
+	// The same threes are forbidden for black.
+	g = initGame("F8G8H9H10a15o1")
+	require.True(t, Forbidden(g.Board(), 7, 7))
+	solution = g.SolveVCT(SolveOptions{MaxDepth: 2})
+	require.NotEqual(t, Win, solution.Result)
+}
+
+func TestSolveBudget(t *testing.T) {
+	g := initGame("f8g8h9h10A15O1")
+	solution := g.SolveVCT(SolveOptions{MaxNodes: 1})
+	require.Equal(t, Unknown, solution.Result)
+	require.Empty(t, solution.Moves)
+}
+
+// requireWinningLine checks that the moves alternate and the attacker's last move can't be defended.
+func requireWinningLine(t *testing.T, g *Game, moves []Move) {
+	require.NotEmpty(t, moves)
+	board := g.Board()
+	attacker := g.Turn()
+	for i, m := range moves {
+		expected := attacker
+		if i%2 == 1 {
+			expected = Opposite(attacker)
+		}
+		require.Equal(t, expected, m.Color(), "move %d", i)
+		require.Equal(t, Nil, board[m.X()*BoardSize+m.Y()], "move %d", i)
+		if i == len(moves)-1 && MakesFive(&board, m.X()*BoardSize+m.Y(), attacker) {
+			return
+		}
+		board[m.X()*BoardSize+m.Y()] = m.Color()
+	}
+	fives := FivePoints(&board, attacker)
+	require.True(t, len(fives) >= 2 || (len(fives) == 1 && !Legal(&board, fives[0], Opposite(attacker))) ||
+		len(winningPoints(&board, attacker)) > 0)
+}
@@ -0,0 +1,242 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/renju24/backend/pkg/game"
+)
+
+// Points are written as a column letter "a"-"o" from the left and a row number 1-15 from the bottom, e.g. "h8" is the center.
+
+var (
+	ErrInvalidPoint  = errors.New("notation: invalid point")
+	ErrInvalidRecord = errors.New("notation: invalid record")
+)
+
+// Results of the record.
+const (
+	BlackWins  = "1-0"
+	WhiteWins  = "0-1"
+	Draw       = "1/2-1/2"
+	Unfinished = "*"
+)
+
+// DateFormat is the format of the Date tag.
+const DateFormat = "2006.01.02"
+
+// RuleRIF is the Rule tag of the standard renju rules.
+const RuleRIF = "RIF"
+
+// FormatPoint returns the point in the board coordinates of pkg/game, e.g. "h8" for 7, 7.
+func FormatPoint(x, y int) string {
+	return string(rune('a'+y)) + strconv.Itoa(game.BoardSize-x)
+}
+
+// ParsePoint parses the point like "h8" or "H8".
+func ParsePoint(s string) (x, y int, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 || s[0] < 'a' || s[0] >= 'a'+game.BoardSize {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return 0, 0, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+		}
+	}
+	row, err := strconv.Atoi(s[1:])
+	if err != nil || row < 1 || row > game.BoardSize || s[1] == '0' {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidPoint, s)
+	}
+	return game.BoardSize - row, int(s[0] - 'a'), nil
+}
+
+// FormatMoves returns the move list like "h8 i9 j10".
+func FormatMoves(moves []game.Move) string {
+	points := make([]string, 0, len(moves))
+	for _, m := range moves {
+		points = append(points, FormatPoint(m.X(), m.Y()))
+	}
+	return strings.Join(points, " ")
+}
+
+// ParseMoves parses the move list, black moves first. Move numbers like "1." and the result are skipped.
+func ParseMoves(s string) ([]game.Move, error) {
+	moves, _, err := parseMovetext(s)
+	return moves, err
+}
+
+// Replay applies the moves to the new game, so they are checked by the rules.
+func Replay(moves []game.Move) (g *game.Game, winner game.Color, err error) {
+	g = game.NewGame()
+	for i, m := range moves {
+		if winner != game.Nil {
+			return nil, game.Nil, fmt.Errorf("%w: move %d after the end of the game", ErrInvalidRecord, i+1)
+		}
+		if winner, err = g.ApplyMove(m); err != nil {
+			return nil, game.Nil, fmt.Errorf("%w: move %d %s: %v", ErrInvalidRecord, i+1, FormatPoint(m.X(), m.Y()), err)
+		}
+	}
+	return g, winner, nil
+}
+
+// Tag is the tag pair of the record which has no field in Record.
+type Tag struct {
+	Name  string
+	Value string
+}
+
+// Record is the game record in RIF/RenjuNet text format: tag pairs and numbered moves, e.g.
+//
+//	[Site "Renju24"]
+//	[Date "2023.05.01"]
+//	[Black "alice"]
+//	[White "bob"]
+//	[Rule "RIF"]
+//	[Result "1-0"]
+//
+//	1. h8 i9 2. j10 ... 1-0
+type Record struct {
+	Event  string
+	Site   string
+	Date   string
+	Round  string
+	Black  string
+	White  string
+	Rule   string
+	Result string
+	// Tags are the other tag pairs in order.
+	Tags  []Tag
+	Moves []game.Move
+}
+
+// String returns the record text, empty tags are omitted, unknown result is "*".
+func (r *Record) String() string {
+	var b strings.Builder
+	result := r.Result
+	if result == "" {
+		result = Unfinished
+	}
+	tags := []Tag{
+		{"Event", r.Event},
+		{"Site", r.Site},
+		{"Date", r.Date},
+		{"Round", r.Round},
+		{"Black", r.Black},
+		{"White", r.White},
+		{"Rule", r.Rule},
+		{"Result", result},
+	}
+	for _, tag := range append(tags, r.Tags...) {
+		if tag.Value != "" {
+			fmt.Fprintf(&b, "[%s \"%s\"]\n", tag.Name, tagEscaper.Replace(tag.Value))
+		}
+	}
+	b.WriteByte('\n')
+	for i, m := range r.Moves {
+		if i%2 == 0 {
+			fmt.Fprintf(&b, "%d. ", i/2+1)
+		}
+		b.WriteString(FormatPoint(m.X(), m.Y()))
+		b.WriteByte(' ')
+	}
+	b.WriteString(result)
+	b.WriteByte('\n')
+	return b.String()
+}
+
+var (
+	tagEscaper    = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	tagUnescaper  = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+	tagRegexp     = regexp.MustCompile(`^\[([A-Za-z0-9_]+)\s+"((?:[^"\\]|\\.)*)"\]$`)
+	numberRegexp  = regexp.MustCompile(`^\d+\.+`)
+	commentRegexp = regexp.MustCompile(`\{[^}]*\}`)
+)
+
+// ParseRecord parses the record. The result is taken from the movetext if there is no Result tag.
+// Moves are checked only for syntax, use Replay to check them by the rules.
+func ParseRecord(s string) (*Record, error) {
+	var (
+		r        Record
+		movetext strings.Builder
+	)
+	for n, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "[") {
+			movetext.WriteString(line)
+			movetext.WriteByte('\n')
+			continue
+		}
+		match := tagRegexp.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("%w: line %d: invalid tag", ErrInvalidRecord, n+1)
+		}
+		r.setTag(match[1], tagUnescaper.Replace(match[2]))
+	}
+	moves, result, err := parseMovetext(movetext.String())
+	if err != nil {
+		return nil, err
+	}
+	r.Moves = moves
+	if r.Result == "" {
+		r.Result = result
+	}
+	if r.Result == "" {
+		r.Result = Unfinished
+	}
+	return &r, nil
+}
+
+func (r *Record) setTag(name, value string) {
+	switch name {
+	case "Event":
+		r.Event = value
+	case "Site":
+		r.Site = value
+	case "Date":
+		r.Date = value
+	case "Round":
+		r.Round = value
+	case "Black":
+		r.Black = value
+	case "White":
+		r.White = value
+	case "Rule":
+		r.Rule = value
+	case "Result":
+		r.Result = value
+	default:
+		r.Tags = append(r.Tags, Tag{Name: name, Value: value})
+	}
+}
+
+// parseMovetext returns the moves with alternating colors and the result token if it's present.
+func parseMovetext(s string) (moves []game.Move, result string, err error) {
+	s = commentRegexp.ReplaceAllString(s, " ")
+	for _, token := range strings.Fields(s) {
+		if result != "" {
+			return nil, "", fmt.Errorf("%w: %q after the result", ErrInvalidRecord, token)
+		}
+		token = numberRegexp.ReplaceAllString(token, "")
+		switch token {
+		case "":
+			continue
+		case BlackWins, WhiteWins, Draw, Unfinished:
+			result = token
+			continue
+		}
+		x, y, err := ParsePoint(token)
+		if err != nil {
+			return nil, "", err
+		}
+		color := game.Black
+		if len(moves)%2 == 1 {
+			color = game.White
+		}
+		moves = append(moves, game.NewMove(x, y, color))
+	}
+	return moves, result, nil
+}
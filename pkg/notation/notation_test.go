@@ -0,0 +1,131 @@
+package notation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/renju24/backend/pkg/game"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePoint(t *testing.T) {
+	testCases := []struct {
+		point string
+		x, y  int
+		err   bool
+	}{
+		{point: "h8", x: 7, y: 7},
+		{point: "H8", x: 7, y: 7},
+		{point: "a1", x: 14, y: 0},
+		{point: "o15", x: 0, y: 14},
+		{point: "a15", x: 0, y: 0},
+		{point: "p1", err: true},
+		{point: "a0", err: true},
+		{point: "a16", err: true},
+		{point: "a08", err: true},
+		{point: "a+8", err: true},
+		{point: "h", err: true},
+		{point: "8h", err: true},
+	}
+	for _, testCase := range testCases {
+		x, y, err := ParsePoint(testCase.point)
+		if testCase.err {
+			require.ErrorIs(t, err, ErrInvalidPoint, testCase.point)
+			continue
+		}
+		require.NoError(t, err, testCase.point)
+		require.Equal(t, testCase.x, x, testCase.point)
+		require.Equal(t, testCase.y, y, testCase.point)
+		require.Equal(t, strings.ToLower(testCase.point), FormatPoint(x, y))
+	}
+}
+
+func TestParseMoves(t *testing.T) {
+	moves, err := ParseMoves("1. h8 i9 2. J10 {comment} i10 3.h10")
+	require.NoError(t, err)
+	require.Equal(t, []game.Move{
+		game.NewMove(7, 7, game.Black),
+		game.NewMove(6, 8, game.White),
+		game.NewMove(5, 9, game.Black),
+		game.NewMove(5, 8, game.White),
+		game.NewMove(5, 7, game.Black),
+	}, moves)
+	require.Equal(t, "h8 i9 j10 i10 h10", FormatMoves(moves))
+
+	_, err = ParseMoves("h8 z9")
+	require.ErrorIs(t, err, ErrInvalidPoint)
+	_, err = ParseMoves("h8 1-0 i9")
+	require.ErrorIs(t, err, ErrInvalidRecord)
+}
+
+func TestRecord(t *testing.T) {
+	moves, err := ParseMoves("h8 h9 i8 i9 j8 j9 k8 k9 l8")
+	require.NoError(t, err)
+	record := &Record{
+		Site:   "Renju24",
+		Date:   "2023.05.01",
+		Black:  "alice",
+		White:  `bob "the builder"`,
+		Rule:   RuleRIF,
+		Result: BlackWins,
+		Tags:   []Tag{{Name: "Game", Value: "42"}},
+		Moves:  moves,
+	}
+	text := record.String()
+	require.Equal(t, `[Site "Renju24"]
+[Date "2023.05.01"]
+[Black "alice"]
+[White "bob \"the builder\""]
+[Rule "RIF"]
+[Result "1-0"]
+[Game "42"]
+
+1. h8 h9 2. i8 i9 3. j8 j9 4. k8 k9 5. l8 1-0
+`, text)
+
+	parsed, err := ParseRecord(text)
+	require.NoError(t, err)
+	require.Equal(t, record, parsed)
+
+	_, winner, err := Replay(parsed.Moves)
+	require.NoError(t, err)
+	require.Equal(t, game.Black, winner)
+}
+
+func TestParseRecord(t *testing.T) {
+	// The result is taken from the movetext, unknown tags are kept.
+	record, err := ParseRecord("[Event \"Open\"]\r\n[Opening \"Sunrise\"]\r\n\r\n1. h8 i9 0-1\r\n")
+	require.NoError(t, err)
+	require.Equal(t, "Open", record.Event)
+	require.Equal(t, WhiteWins, record.Result)
+	require.Equal(t, []Tag{{Name: "Opening", Value: "Sunrise"}}, record.Tags)
+	require.Len(t, record.Moves, 2)
+
+	record, err = ParseRecord("h8")
+	require.NoError(t, err)
+	require.Equal(t, Unfinished, record.Result)
+
+	_, err = ParseRecord("[Black alice]\n\nh8")
+	require.ErrorIs(t, err, ErrInvalidRecord)
+}
+
+func TestReplay(t *testing.T) {
+	// The first move must be in the center.
+	moves, err := ParseMoves("a1 h8")
+	require.NoError(t, err)
+	_, _, err = Replay(moves)
+	require.ErrorIs(t, err, ErrInvalidRecord)
+
+	// No moves after five.
+	moves, err = ParseMoves("h8 h9 i8 i9 j8 j9 k8 k9 l8 l9")
+	require.NoError(t, err)
+	_, _, err = Replay(moves)
+	require.ErrorIs(t, err, ErrInvalidRecord)
+
+	moves, err = ParseMoves("h8 i9")
+	require.NoError(t, err)
+	g, winner, err := Replay(moves)
+	require.NoError(t, err)
+	require.Equal(t, game.Nil, winner)
+	require.Equal(t, game.Black, g.Turn())
+}
@@ -8,31 +8,201 @@ CREATE TABLE users (
 	username        VARCHAR(32)  NOT NULL,
 	email           VARCHAR(84)  NULL,
 	password_bcrypt VARCHAR(128) NULL,
-	google_id       VARCHAR(64)  NULL,
-	yandex_id       VARCHAR(64)  NULL,
-	vk_id           VARCHAR(64)  NULL,
-	ranking         INT          NOT NULL DEFAULT 400
-);
-CREATE UNIQUE INDEX unique_google_id ON users (google_id);
-CREATE UNIQUE INDEX unique_yandex_id ON users (yandex_id);
-CREATE UNIQUE INDEX unique_vk_id ON users (vk_id);
+	ranking         INT          NOT NULL DEFAULT 400,
+	is_admin        BOOLEAN      NOT NULL DEFAULT FALSE,
+	email_verified  BOOLEAN      NOT NULL DEFAULT FALSE,
+	sessions_revoked_at TIMESTAMP(0) NULL,
+	username_changed_at TIMESTAMP(0) NULL,
+	totp_secret     VARCHAR(64)  NULL,
+	totp_enabled    BOOLEAN      NOT NULL DEFAULT FALSE,
+	totp_last_counter BIGINT     NULL,
+	display_name    VARCHAR(32)  NOT NULL DEFAULT '',
+	bio             VARCHAR(280) NOT NULL DEFAULT '',
+	country         CHAR(2)      NULL,
+	language        VARCHAR(16)  NULL,
+	board_theme     VARCHAR(16)  NOT NULL DEFAULT 'classic',
+	board_coordinates BOOLEAN    NOT NULL DEFAULT TRUE,
+	move_numbers    BOOLEAN      NOT NULL DEFAULT FALSE,
+	avatar_updated_at TIMESTAMP(0) NULL,
+	-- Bot is the system user which plays against users, it can't sign in.
+	is_bot          BOOLEAN      NOT NULL DEFAULT FALSE,
+	-- Deleted users are anonymised, the rows are kept for opponents' games.
+	deleted_at      TIMESTAMP(0) NULL
+);
 CREATE UNIQUE INDEX unique_username ON users (username);
 CREATE UNIQUE INDEX unique_email ON users (email);
 
+-- User can link one account of each OAuth2 service, services are configured in the provider registry.
+CREATE TABLE oauth_accounts (
+	user_id         INT          NOT NULL REFERENCES users(id),
+	service         VARCHAR(32)  NOT NULL,
+	oauth_id        VARCHAR(64)  NOT NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX unique_oauth_account ON oauth_accounts (service, oauth_id);
+CREATE UNIQUE INDEX unique_user_service ON oauth_accounts (user_id, service);
+
 CREATE TABLE games (
 	id              SERIAL       PRIMARY KEY,
 	black_user_id   INT          NOT NULL REFERENCES users(id),
 	white_user_id   INT          NOT NULL REFERENCES users(id),
 	winner_id       INT          NULL     REFERENCES users(id),
 	status          INT          NOT NULL,
+	rated           BOOLEAN      NOT NULL DEFAULT TRUE,
 	started_at      TIMESTAMP(0) NULL,
-	finished_at     TIMESTAMP(0) NULL
+	finished_at     TIMESTAMP(0) NULL,
+	-- Level of the bot opponent, NULL if both players are users.
+	bot_level       INT          NULL
 );
 
 CREATE TABLE moves (
+	id              SERIAL PRIMARY KEY,
 	game_id         INT NOT NULL REFERENCES games(id),
 	user_id         INT NOT NULL REFERENCES users(id),
 	x_coordinate    INT NOT NULL,
 	y_coordinate    INT NOT NULL
 );
 CREATE INDEX moves_game_id ON moves (game_id);
+
+CREATE TABLE rating_history (
+	user_id         INT          NOT NULL REFERENCES users(id),
+	game_id         INT          NOT NULL REFERENCES games(id),
+	rating_before   INT          NOT NULL,
+	rating_after    INT          NOT NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, game_id)
+);
+
+CREATE TABLE messages (
+	id              SERIAL        PRIMARY KEY,
+	game_id         INT           NOT NULL REFERENCES games(id),
+	user_id         INT           NOT NULL REFERENCES users(id),
+	text            VARCHAR(1024) NOT NULL,
+	sent_at         TIMESTAMP(0)  NOT NULL DEFAULT NOW(),
+	deleted_at      TIMESTAMP(0)  NULL
+);
+CREATE INDEX messages_game_id ON messages (game_id);
+
+CREATE TABLE mutes (
+	user_id         INT          PRIMARY KEY REFERENCES users(id),
+	muted_by        INT          NOT NULL REFERENCES users(id),
+	reason          VARCHAR(256) NOT NULL,
+	muted_until     TIMESTAMP(0) NOT NULL
+);
+
+CREATE TABLE reports (
+	id              SERIAL       PRIMARY KEY,
+	message_id      INT          NOT NULL REFERENCES messages(id),
+	reporter_id     INT          NOT NULL REFERENCES users(id),
+	reason          VARCHAR(256) NOT NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	resolved_by     INT          NULL     REFERENCES users(id),
+	resolved_at     TIMESTAMP(0) NULL
+);
+CREATE UNIQUE INDEX unique_report ON reports (message_id, reporter_id);
+
+CREATE TABLE friends (
+	requester_id    INT          NOT NULL REFERENCES users(id),
+	addressee_id    INT          NOT NULL REFERENCES users(id),
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	accepted_at     TIMESTAMP(0) NULL,
+	PRIMARY KEY (requester_id, addressee_id)
+);
+CREATE INDEX friends_addressee_id ON friends (addressee_id);
+-- A pair of users has one row, whoever sent the request.
+CREATE UNIQUE INDEX unique_friendship ON friends (LEAST(requester_id, addressee_id), GREATEST(requester_id, addressee_id));
+
+CREATE TABLE blocks (
+	user_id         INT          NOT NULL REFERENCES users(id),
+	blocked_user_id INT          NOT NULL REFERENCES users(id),
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (user_id, blocked_user_id)
+);
+CREATE INDEX blocks_blocked_user_id ON blocks (blocked_user_id);
+
+CREATE TABLE notifications (
+	id              SERIAL       PRIMARY KEY,
+	user_id         INT          NOT NULL REFERENCES users(id),
+	event_type      VARCHAR(64)  NOT NULL,
+	data            JSONB        NOT NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	read_at         TIMESTAMP(0) NULL,
+	digested_at     TIMESTAMP(0) NULL
+);
+CREATE INDEX notifications_user_id ON notifications (user_id, id);
+CREATE INDEX notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+
+CREATE TABLE push_subscriptions (
+	id              SERIAL        PRIMARY KEY,
+	user_id         INT           NOT NULL REFERENCES users(id),
+	endpoint        VARCHAR(1024) NOT NULL,
+	p256dh          VARCHAR(128)  NOT NULL,
+	auth            VARCHAR(64)   NOT NULL,
+	created_at      TIMESTAMP(0)  NOT NULL DEFAULT NOW()
+);
+CREATE UNIQUE INDEX unique_push_endpoint ON push_subscriptions (endpoint);
+CREATE INDEX push_subscriptions_user_id ON push_subscriptions (user_id);
+
+CREATE TABLE email_outbox (
+	id              SERIAL       PRIMARY KEY,
+	recipient       VARCHAR(84)  NOT NULL,
+	subject         VARCHAR(256) NOT NULL,
+	text_body       TEXT         NOT NULL,
+	html_body       TEXT         NOT NULL,
+	attempts        INT          NOT NULL DEFAULT 0,
+	last_error      TEXT         NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	next_attempt_at TIMESTAMP(0) NULL     DEFAULT NOW(),
+	sent_at         TIMESTAMP(0) NULL
+);
+CREATE INDEX email_outbox_pending ON email_outbox (next_attempt_at) WHERE sent_at IS NULL;
+
+CREATE TABLE email_verification_tokens (
+	token_hash      CHAR(64)     PRIMARY KEY,
+	user_id         INT          NOT NULL REFERENCES users(id),
+	email           VARCHAR(84)  NOT NULL,
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	expires_at      TIMESTAMP(0) NOT NULL,
+	used_at         TIMESTAMP(0) NULL
+);
+CREATE INDEX email_verification_tokens_user_id ON email_verification_tokens (user_id);
+
+CREATE TABLE password_reset_tokens (
+	token_hash      CHAR(64)     PRIMARY KEY,
+	user_id         INT          NOT NULL REFERENCES users(id),
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	expires_at      TIMESTAMP(0) NOT NULL,
+	used_at         TIMESTAMP(0) NULL
+);
+CREATE INDEX password_reset_tokens_user_id ON password_reset_tokens (user_id);
+
+-- Refresh tokens rotate on every use, the previous token is kept to detect its reuse.
+CREATE TABLE sessions (
+	id              SERIAL       PRIMARY KEY,
+	user_id         INT          NOT NULL REFERENCES users(id),
+	token_hash      CHAR(64)     NOT NULL,
+	previous_token_hash CHAR(64) NULL,
+	user_agent      VARCHAR(256) NOT NULL DEFAULT '',
+	ip              VARCHAR(45)  NOT NULL DEFAULT '',
+	created_at      TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	last_used_at    TIMESTAMP(0) NOT NULL DEFAULT NOW(),
+	expires_at      TIMESTAMP(0) NOT NULL,
+	revoked_at      TIMESTAMP(0) NULL
+);
+CREATE UNIQUE INDEX unique_session_token ON sessions (token_hash);
+CREATE INDEX sessions_previous_token_hash ON sessions (previous_token_hash);
+CREATE INDEX sessions_user_id ON sessions (user_id);
+
+CREATE TABLE recovery_codes (
+	user_id         INT          NOT NULL REFERENCES users(id),
+	code_hash       CHAR(64)     NOT NULL,
+	used_at         TIMESTAMP(0) NULL,
+	PRIMARY KEY (user_id, code_hash)
+);
+
+-- Old usernames can't be taken by other users for a while after renaming.
+CREATE TABLE reserved_usernames (
+	username        VARCHAR(32)  PRIMARY KEY,
+	user_id         INT          NOT NULL REFERENCES users(id),
+	reserved_until  TIMESTAMP(0) NOT NULL
+);